@@ -0,0 +1,124 @@
+package gtree
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// BranchStyle is a set of branches used for drawing tree.
+// "Directly" is the branch of the row of the node itself, "Indirectly" is the branch drawn in the rows of its descendants.
+type BranchStyle struct {
+	IntermedialDirectly   string
+	IntermedialIndirectly string
+	LastDirectly          string
+	LastIndirectly        string
+}
+
+var (
+	// ErrExistBranchStyle is returned when the branch style with the same name has already been registered.
+	ErrExistBranchStyle = errors.New("branch style already exists")
+	// ErrUnknownBranchStyle is returned when the branch style with the name has not been registered.
+	ErrUnknownBranchStyle = errors.New("unknown branch style")
+)
+
+var branchStyles = struct {
+	mu     sync.RWMutex
+	styles map[string]BranchStyle
+}{
+	styles: map[string]BranchStyle{
+		"default": {
+			IntermedialDirectly:   "├──",
+			IntermedialIndirectly: "│   ",
+			LastDirectly:          "└──",
+			LastIndirectly:        "    ",
+		},
+		"ascii": {
+			IntermedialDirectly:   "|--",
+			IntermedialIndirectly: "|   ",
+			LastDirectly:          "`--",
+			LastIndirectly:        "    ",
+		},
+		"rounded": {
+			IntermedialDirectly:   "├──",
+			IntermedialIndirectly: "│   ",
+			LastDirectly:          "╰──",
+			LastIndirectly:        "    ",
+		},
+		"bold": {
+			IntermedialDirectly:   "┣━━",
+			IntermedialIndirectly: "┃   ",
+			LastDirectly:          "┗━━",
+			LastIndirectly:        "    ",
+		},
+		"double": {
+			IntermedialDirectly:   "╠══",
+			IntermedialIndirectly: "║   ",
+			LastDirectly:          "╚══",
+			LastIndirectly:        "    ",
+		},
+		"indent-only": {
+			IntermedialDirectly:   "   ",
+			IntermedialIndirectly: "    ",
+			LastDirectly:          "   ",
+			LastIndirectly:        "    ",
+		},
+	},
+}
+
+// RegisterBranchStyle registers style with name so that it can be specified by WithBranchStyle.
+// It returns ErrExistBranchStyle if the name has already been registered, including the presets.
+func RegisterBranchStyle(name string, style BranchStyle) error {
+	branchStyles.mu.Lock()
+	defer branchStyles.mu.Unlock()
+
+	if _, ok := branchStyles.styles[name]; ok {
+		return ErrExistBranchStyle
+	}
+	branchStyles.styles[name] = style
+	return nil
+}
+
+// LookupBranchStyle returns the branch style registered with name.
+func LookupBranchStyle(name string) (BranchStyle, bool) {
+	branchStyles.mu.RLock()
+	defer branchStyles.mu.RUnlock()
+
+	style, ok := branchStyles.styles[name]
+	return style, ok
+}
+
+// BranchStyleNames returns the names of the registered branch styles in ascending order.
+func BranchStyleNames() []string {
+	branchStyles.mu.RLock()
+	defer branchStyles.mu.RUnlock()
+
+	names := make([]string, 0, len(branchStyles.styles))
+	for name := range branchStyles.styles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// WithBranchStyle returns function for drawing tree with the branch style registered with name.
+// The presets are "default", "ascii", "rounded", "bold", "double" and "indent-only".
+// If the name has not been registered, the functions that take this option return ErrUnknownBranchStyle.
+func WithBranchStyle(name string) Option {
+	return func(c *config) {
+		style, ok := LookupBranchStyle(name)
+		if !ok {
+			c.err = fmt.Errorf("%w: %q", ErrUnknownBranchStyle, name)
+			return
+		}
+		c.intermedialNodeFormat = branchFormat{
+			directly:   style.IntermedialDirectly,
+			indirectly: style.IntermedialIndirectly,
+		}
+		c.lastNodeFormat = branchFormat{
+			directly:   style.LastDirectly,
+			indirectly: style.LastIndirectly,
+		}
+	}
+}
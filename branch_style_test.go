@@ -0,0 +1,53 @@
+package gtree_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ddddddO/gtree"
+)
+
+func TestRegisterBranchStyle(t *testing.T) {
+	style := gtree.BranchStyle{
+		IntermedialDirectly:   "+->",
+		IntermedialIndirectly: "|   ",
+		LastDirectly:          "+->",
+		LastIndirectly:        "    ",
+	}
+	if err := gtree.RegisterBranchStyle("arrow", style); err != nil {
+		t.Fatal(err)
+	}
+	if err := gtree.RegisterBranchStyle("arrow", style); !errors.Is(err, gtree.ErrExistBranchStyle) {
+		t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", err, gtree.ErrExistBranchStyle)
+	}
+	if err := gtree.RegisterBranchStyle("default", style); !errors.Is(err, gtree.ErrExistBranchStyle) {
+		t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", err, gtree.ErrExistBranchStyle)
+	}
+
+	if got, ok := gtree.LookupBranchStyle("arrow"); !ok || got != style {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", got, style)
+	}
+	if got := gtree.BranchStyleNames(); !slices.Contains(got, "arrow") || !slices.IsSorted(got) {
+		t.Errorf("\ngot: \n%v\nwant: \nsorted names containing arrow", got)
+	}
+
+	root := gtree.NewRoot("a")
+	root.Add("b").Add("c")
+	root.Add("d")
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root, gtree.WithBranchStyle("arrow")); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+a
++-> b
+|   +-> c
++-> d
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/ddddddO/gtree"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+func diff(oldPath, newPath string, options []gtree.Option, asJSON bool) error {
+	oldRoots, err := readRoots(oldPath, options)
+	if err != nil {
+		return err
+	}
+	newRoots, err := readRoots(newPath, options)
+	if err != nil {
+		return err
+	}
+
+	// Rootは出現順で対応付ける
+	pairs := max(len(oldRoots), len(newRoots))
+	rootAt := func(roots []*gtree.Node, i int) *gtree.Node {
+		if i < len(roots) {
+			return roots[i]
+		}
+		return nil
+	}
+
+	if asJSON {
+		changes := []gtree.Change{}
+		for i := 0; i < pairs; i++ {
+			changes = append(changes, gtree.Diff(rootAt(oldRoots, i), rootAt(newRoots, i))...)
+		}
+		return json.NewEncoder(os.Stdout).Encode(changes)
+	}
+
+	for i := 0; i < pairs; i++ {
+		if err := gtree.OutputDiff(color.Output, rootAt(oldRoots, i), rootAt(newRoots, i), options...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRoots(path string, options []gtree.Option) ([]*gtree.Node, error) {
+	if isInputStdin(path) {
+		roots, err := buildRoots(os.Stdin, options)
+		return roots, withInputPath(err, path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	roots, err := buildRoots(f, options)
+	return roots, withInputPath(err, path)
+}
+
+// gtree.Walk で辿ったノードから *gtree.Node のツリーを組み立て直す
+func buildRoots(r io.Reader, options []gtree.Option) ([]*gtree.Node, error) {
+	roots := []*gtree.Node{}
+	ancestors := []*gtree.Node{}
+	if err := gtree.Walk(r, func(wn *gtree.WalkerNode) error {
+		level := int(wn.Level())
+		if level == 1 {
+			root := gtree.NewRoot(wn.Name())
+			roots = append(roots, root)
+			ancestors = []*gtree.Node{root}
+			return nil
+		}
+
+		current := ancestors[level-2].Add(wn.Name())
+		ancestors = append(ancestors[:level-1], current)
+		return nil
+	}, options...); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+func optionDiffOutput(c *cli.Context) (bool, error) {
+	switch c.String("format") {
+	case "tree", "":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, errors.New(`specify either "tree" or "json"`)
+	}
+}
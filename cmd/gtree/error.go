@@ -1,6 +1,12 @@
 package main
 
-import "github.com/urfave/cli/v2"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ddddddO/gtree"
+	"github.com/urfave/cli/v2"
+)
 
 const (
 	exitCodeErrOpts = iota + 1
@@ -8,8 +14,16 @@ const (
 	exitCodeErrOutput
 	exitCodeErrMkdir
 	exitCodeErrVerify
+	exitCodeErrLint
+	exitCodeErrScan
+	exitCodeErrFmt
+	exitCodeErrDiff
+	exitCodeErrStats
 )
 
+// gofmt などと同様に、整形されていない入力があれば 1 で終了する
+const exitCodeNotFormatted = 1
+
 func exitErrOpts(err error) cli.ExitCoder {
 	return cli.Exit(err, exitCodeErrOpts)
 }
@@ -29,3 +43,40 @@ func exitErrMkdir(err error) cli.ExitCoder {
 func exitErrVerify(err error) cli.ExitCoder {
 	return cli.Exit(err, exitCodeErrVerify)
 }
+
+func exitErrLint(err error) cli.ExitCoder {
+	return cli.Exit(err, exitCodeErrLint)
+}
+
+func exitErrScan(err error) cli.ExitCoder {
+	return cli.Exit(err, exitCodeErrScan)
+}
+
+func exitErrFmt(err error) cli.ExitCoder {
+	return cli.Exit(err, exitCodeErrFmt)
+}
+
+func exitErrDiff(err error) cli.ExitCoder {
+	return cli.Exit(err, exitCodeErrDiff)
+}
+
+func exitErrStats(err error) cli.ExitCoder {
+	return cli.Exit(err, exitCodeErrStats)
+}
+
+func exitNotFormatted(err error) cli.ExitCoder {
+	return cli.Exit(err, exitCodeNotFormatted)
+}
+
+// withInputPath prefixes the markdown path to the line and column of gtree.ParseError.
+// e.g. "file.md:42:5: incorrect input format: ..."
+func withInputPath(err error, path string) error {
+	var pe *gtree.ParseError
+	if !errors.As(err, &pe) {
+		return err
+	}
+	if isInputStdin(path) {
+		path = "<stdin>"
+	}
+	return fmt.Errorf("%s:%w", path, err)
+}
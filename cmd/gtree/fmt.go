@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ddddddO/gtree"
+	"github.com/urfave/cli/v2"
+)
+
+type formatter struct {
+	options []gtree.Option
+	write   bool
+	check   bool
+	w       io.Writer
+
+	// --check で整形されていなかった入力
+	unformatted []string
+}
+
+func newFormatter(w io.Writer, options []gtree.Option, write, check bool) *formatter {
+	return &formatter{
+		options: append([]gtree.Option{gtree.WithEncodeMarkdown()}, options...),
+		write:   write,
+		check:   check,
+		w:       w,
+	}
+}
+
+func (f *formatter) formatStdin(in io.Reader) error {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	dst, err := f.format(src, "")
+	if err != nil {
+		return err
+	}
+
+	if f.check {
+		if !bytes.Equal(src, dst) {
+			f.unformatted = append(f.unformatted, "<stdin>")
+			fmt.Fprintln(f.w, "<stdin>")
+		}
+		return nil
+	}
+	_, err = f.w.Write(dst)
+	return err
+}
+
+func (f *formatter) formatFile(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dst, err := f.format(src, path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case f.check:
+		if !bytes.Equal(src, dst) {
+			f.unformatted = append(f.unformatted, path)
+			fmt.Fprintln(f.w, path)
+		}
+		return nil
+	case f.write:
+		if bytes.Equal(src, dst) {
+			return nil
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, dst, fi.Mode().Perm())
+	default:
+		_, err := f.w.Write(dst)
+		return err
+	}
+}
+
+func (f *formatter) format(src []byte, path string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := gtree.Output(buf, bytes.NewReader(src), f.options...); err != nil {
+		return nil, withInputPath(err, path)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *formatter) result() error {
+	if len(f.unformatted) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d input(s) not formatted", len(f.unformatted))
+}
+
+func optionFmt(c *cli.Context) ([]gtree.Option, error) {
+	options := []gtree.Option{}
+
+	switch bullet := c.String("bullet"); bullet {
+	case "-", "*", "+":
+		options = append(options, gtree.WithMarkdownBullet(bullet))
+	default:
+		return nil, errors.New(`specify either "-" or "*" or "+"`)
+	}
+
+	if indent := c.Int("indent"); indent < 0 {
+		return nil, errors.New("the indent width should be 0 or greater.")
+	} else if indent > 0 {
+		options = append(options, gtree.WithMarkdownIndent(indent))
+	}
+	return options, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ddddddO/gtree"
+)
+
+func lint(in io.Reader, markdownPath string) error {
+	diagnostics := gtree.Lint(in)
+	if len(diagnostics) == 0 {
+		return nil
+	}
+
+	if isInputStdin(markdownPath) {
+		markdownPath = "<stdin>"
+	}
+	msgs := make([]string, len(diagnostics))
+	for i := range diagnostics {
+		msgs[i] = fmt.Sprintf("%s:%s", markdownPath, diagnostics[i])
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ddddddO/gtree"
@@ -28,6 +29,22 @@ func main() {
 		},
 	}
 
+	inputFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "input-format",
+			Usage:       `set this option when specifying input format. "markdown", "tree", "json", "yaml", "toml"`,
+			DefaultText: "markdown",
+		},
+	}
+
+	sortFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "sort",
+			Usage:       `set this option if you want to sort sibling nodes. "name", "name-desc", "natural", "dirs-first". orders can be combined with ",", e.g. "dirs-first,natural". files for "dirs-first" are determined by extensions.`,
+			DefaultText: "input order",
+		},
+	}
+
 	outputFlags := []cli.Flag{
 		&cli.BoolFlag{
 			Name:    "massive",
@@ -47,16 +64,29 @@ func main() {
 		},
 		&cli.StringFlag{
 			Name:  "format",
-			Usage: `set this option when specifying output format. "json", "yaml", "toml"`,
+			Usage: `set this option when specifying output format. "json", "yaml", "toml", "markdown", "mermaid", "dot", "html"`,
 		},
 		&cli.BoolFlag{
 			Name:    "watch",
 			Aliases: []string{"w"},
 			Usage:   "follow changes in markdown file.",
 		},
+		&cli.PathFlag{
+			Name:  "template",
+			Usage: "set this option if you want to render the tree with a text/template file instead of --format. the template is executed for each root with the root node that has Name, Branch, Row, Path, Level, IsLast, IsFile, IsSummary, Comment, Meta and Children, and the Nodes method that returns the node and all of its descendants.",
+		},
+		&cli.BoolFlag{
+			Name:  "summary",
+			Usage: "set this option if you want to output the number of directories and files after the tree. nodes without children are counted as files unless extensions are specified.",
+		},
 	}
 
 	mkdirFlags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "massive",
+			Aliases: []string{"m"},
+			Usage:   "set this option when there are very many blocks of markdown. the order of made directories is not guaranteed.",
+		},
 		&cli.BoolFlag{
 			Name:    "dry-run",
 			Aliases: []string{"d"},
@@ -72,6 +102,22 @@ func main() {
 			Usage:       "set this option if you want to specify the directory you want to make directory.",
 			DefaultText: "current directory",
 		},
+		&cli.BoolFlag{
+			Name:  "merge",
+			Usage: "set this option if you want to make only the missing directories and files when the root already exists. existing files are never truncated and reported as skipped.",
+		},
+		&cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "set this option if you want to make the missing directories and files and rewrite the existing files when the root already exists.",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "set this option if you want to output the result of each path as JSON instead of a list. the status is \"created_dir\", \"created_file\", \"skipped\", \"overwritten\" or \"failed\".",
+		},
+		&cli.BoolFlag{
+			Name:  "rollback",
+			Usage: "set this option if you want to remove the directories and files made and restore the overwritten files when making fails halfway. existing paths are left untouched.",
+		},
 	}
 
 	verifyFlags := []cli.Flag{
@@ -87,6 +133,152 @@ func main() {
 		},
 	}
 
+	scanFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       `set this option when specifying output format. "markdown", "tree", "json", "yaml", "toml", "mermaid", "dot", "html"`,
+			DefaultText: "markdown",
+		},
+		&cli.IntFlag{
+			Name:        "max-depth",
+			Aliases:     []string{"L"},
+			Usage:       "set this option if you want to limit the depth of directories.",
+			DefaultText: "unlimited",
+		},
+		&cli.BoolFlag{
+			Name:    "all",
+			Aliases: []string{"a"},
+			Usage:   "set this option if you want to include files and directories starting with \".\".",
+		},
+		&cli.BoolFlag{
+			Name:  "gitignore",
+			Usage: "set this option if you want to exclude paths ignored by .gitignore.",
+		},
+		&cli.BoolFlag{
+			Name:    "dirs-only",
+			Aliases: []string{"d"},
+			Usage:   "set this option if you want to list directories only.",
+		},
+	}
+
+	templateDataFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "set",
+			Usage: "set this option if you want to render variables in markdown, e.g. \"- {{.Service}}-api\". for example: \"--set Service=billing\". it can be specified multiple times and overrides --values.",
+		},
+		&cli.PathFlag{
+			Name:  "values",
+			Usage: "set this option if you want to render variables in markdown with the values in a YAML file.",
+		},
+	}
+
+	maxChildrenFlag := &cli.IntFlag{
+		Name:        "max-children",
+		Usage:       "set this option if you want to show only the first n children of each node. the rest are summarized in a row.",
+		DefaultText: "unlimited",
+	}
+
+	compactFlag := &cli.BoolFlag{
+		Name:  "compact",
+		Usage: "set this option if you want to show each chain of directories with only one child directory in a row, e.g. \"com/example/app\".",
+	}
+
+	truncationFlags := []cli.Flag{
+		&cli.IntFlag{
+			Name:        "max-depth",
+			Aliases:     []string{"L"},
+			Usage:       "set this option if you want to limit the depth of the tree. the root is depth 0. deeper nodes are summarized in a row.",
+			DefaultText: "unlimited",
+		},
+		maxChildrenFlag,
+	}
+
+	filterFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "match",
+			Usage: "set this option if you want to keep only the nodes whose names match the glob and their ancestors. for example: \"--match '*.proto'\". it can be specified multiple times.",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "set this option if you want to drop the nodes whose names match the glob and their descendants. it can be specified multiple times.",
+		},
+		&cli.BoolFlag{
+			Name:  "highlight",
+			Usage: "set this option if you want to color the names of the nodes matched by --match.",
+		},
+	}
+
+	branchFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "style",
+			Usage:       fmt.Sprintf("set this option if you want to change the look of branches. %s. \"ascii\" is useful where box-drawing characters are not displayed correctly.", quoteJoin(gtree.BranchStyleNames(), ", ")),
+			DefaultText: "default",
+		},
+		&cli.StringFlag{
+			Name:  "branch-intermediate",
+			Usage: "set this option if you want to specify the branch of intermediate nodes as \"<directly>,<indirectly>\", e.g. \"+--,:   \". if \",<indirectly>\" is omitted, that of the style is used.",
+		},
+		&cli.StringFlag{
+			Name:  "branch-last",
+			Usage: "set this option if you want to specify the branch of last nodes as \"<directly>,<indirectly>\", e.g. \"+--,    \". if \",<indirectly>\" is omitted, that of the style is used.",
+		},
+	}
+
+	htmlFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "extension",
+			Aliases: []string{"e"},
+			Usage:   "set this option if you want to distinguish files from directories in html output and --summary. for example, for files with \".go\" extension: \"-e .go\"",
+		},
+		&cli.BoolFlag{
+			Name:  "html-style",
+			Usage: "set this option if you want to embed the default stylesheet in html output.",
+		},
+	}
+
+	fmtFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "bullet",
+			Usage: `set this option when specifying the bullet of each row. "-", "*", "+"`,
+			Value: "-",
+		},
+		&cli.IntFlag{
+			Name:        "indent",
+			Usage:       "set this option if you want to indent with the specified number of spaces.",
+			DefaultText: "tab",
+		},
+		&cli.BoolFlag{
+			Name:    "write",
+			Aliases: []string{"w"},
+			Usage:   "write result to the file instead of stdout.",
+		},
+		&cli.BoolFlag{
+			Name:  "check",
+			Usage: "list the files whose formatting differs and exit with status 1 if any. the files are not modified.",
+		},
+	}
+
+	diffFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       `set this option when specifying output format. "tree", "json"`,
+			DefaultText: "tree",
+		},
+	}
+
+	statsFlags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "extension",
+			Aliases: []string{"e"},
+			Usage:   "set this option if you want to count only the nodes with the extensions as files. by default, nodes without children are counted as files.",
+		},
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       `set this option when specifying output format. "text", "json"`,
+			DefaultText: "text",
+		},
+	}
+
 	templateFlags := []cli.Flag{
 		&cli.BoolFlag{
 			Name:    "description",
@@ -116,7 +308,7 @@ func main() {
 				Aliases: []string{"o", "out"},
 				Usage: "Outputs tree from markdown.\n" +
 					"Let's try 'gtree template | gtree output'.",
-				Flags:  append(commonFlags, outputFlags...),
+				Flags:  concatFlags(commonFlags, inputFlags, templateDataFlags, outputFlags, sortFlags, truncationFlags, filterFlags, []cli.Flag{compactFlag}, branchFlags, htmlFlags),
 				Before: notExistArgs,
 				Action: actionOutput,
 			},
@@ -125,7 +317,7 @@ func main() {
 				Aliases: []string{"m"},
 				Usage: "Makes directories and files from markdown. It is possible to dry run.\n" +
 					"Let's try 'gtree template | gtree mkdir -e .go -e .md -e Makefile'.",
-				Flags:  append(commonFlags, mkdirFlags...),
+				Flags:  concatFlags(commonFlags, inputFlags, templateDataFlags, mkdirFlags, sortFlags),
 				Before: notExistArgs,
 				Action: actionMkdir,
 			},
@@ -134,10 +326,50 @@ func main() {
 				Aliases: []string{"vf"},
 				Usage: "Verifies tree structure represented in markdown by comparing it with existing directories.\n" +
 					"Let's try 'gtree template | gtree verify'.",
-				Flags:  append(commonFlags, verifyFlags...),
+				Flags:  concatFlags(commonFlags, inputFlags, templateDataFlags, verifyFlags),
 				Before: notExistArgs,
 				Action: actionVerify,
 			},
+			{
+				Name: "scan",
+				Usage: "Outputs markdown (or tree) from an existing directory. The markdown can be used with 'gtree verify'.\n" +
+					"Let's try 'gtree scan . > tree.md'.",
+				ArgsUsage: "[directory]",
+				Flags:     concatFlags(scanFlags, []cli.Flag{maxChildrenFlag, compactFlag}, sortFlags, htmlFlags),
+				Action:    actionScan,
+			},
+			{
+				Name: "lint",
+				Usage: "Reports all problems in markdown at once. Exits with non-zero status if any problem is found.\n" +
+					"Let's try 'gtree template | gtree lint'.",
+				Flags:  commonFlags,
+				Before: notExistArgs,
+				Action: actionLint,
+			},
+			{
+				Name: "diff",
+				Usage: "Outputs added, removed, moved and renamed nodes between two markdown files as a unified tree.\n" +
+					"Let's try 'gtree diff old.md new.md' before running 'gtree mkdir'.",
+				ArgsUsage: "<old markdown> <new markdown>",
+				Flags:     concatFlags(inputFlags, diffFlags),
+				Action:    actionDiff,
+			},
+			{
+				Name: "stats",
+				Usage: "Outputs the number of nodes, directories and files, the max depth, the widest level, the number of files per extension and the largest subtrees of each tree.\n" +
+					"Let's try 'gtree template | gtree stats --format json'.",
+				Flags:  concatFlags(commonFlags, inputFlags, statsFlags),
+				Before: notExistArgs,
+				Action: actionStats,
+			},
+			{
+				Name: "fmt",
+				Usage: "Formats markdown in a canonical style, similar to gofmt. Without files, formats markdown from stdin.\n" +
+					"Let's try 'gtree template | gtree fmt --indent 2'.",
+				ArgsUsage: "[files...]",
+				Flags:     concatFlags(fmtFlags, sortFlags),
+				Action:    actionFmt,
+			},
 			{
 				Name:    "template",
 				Aliases: []string{"t", "tmpl"},
@@ -172,6 +404,14 @@ func main() {
 	}
 }
 
+func concatFlags(flagsList ...[]cli.Flag) []cli.Flag {
+	ret := []cli.Flag{}
+	for _, flags := range flagsList {
+		ret = append(ret, flags...)
+	}
+	return ret
+}
+
 func notExistArgs(c *cli.Context) error {
 	if c.NArg() != 0 {
 		return errors.New("command line contains unnecessary arguments")
@@ -184,6 +424,10 @@ func actionOutput(c *cli.Context) error {
 	if err != nil {
 		return exitErrOpts(err)
 	}
+	oi, err := optionInput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
 	var om gtree.Option
 	if c.Bool("massive") {
 		om = gtree.WithMassive(context.Background())
@@ -193,19 +437,43 @@ func actionOutput(c *cli.Context) error {
 		defer cancel()
 		om = gtree.WithMassive(ctx)
 	}
-	options := []gtree.Option{oo, om}
+	sortOpt, err := optionSort(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	ob, err := optionBranch(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	of, err := optionFilter(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	ot, err := optionTemplateData(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	options := []gtree.Option{oo, oi, ot, om, sortOpt, gtree.WithMaxDepth(c.Int("max-depth")), gtree.WithMaxChildren(c.Int("max-children")), gtree.WithFileExtensions(c.StringSlice("extension")), optionHTMLStyle(c)}
+	options = append(options, ob...)
+	options = append(options, of...)
+	if c.Bool("compact") {
+		options = append(options, gtree.WithCompactChains())
+	}
+	if c.Bool("summary") {
+		options = append(options, gtree.WithSummary())
+	}
 
 	markdownPath := c.Path("file")
 	if isInputStdin(markdownPath) {
 		if err := output(os.Stdin, options); err != nil {
-			return exitErrOutput(err)
+			return exitErrOutput(withInputPath(err, markdownPath))
 		}
 		return nil
 	}
 
 	if c.Bool("watch") {
 		if err := outputContinuously(markdownPath, options); err != nil {
-			return exitErrOutput(err)
+			return exitErrOutput(withInputPath(err, markdownPath))
 		}
 	} else {
 		f, err := os.Open(markdownPath)
@@ -215,7 +483,7 @@ func actionOutput(c *cli.Context) error {
 		defer f.Close()
 
 		if err := output(f, options); err != nil {
-			return exitErrOutput(err)
+			return exitErrOutput(withInputPath(err, markdownPath))
 		}
 	}
 
@@ -235,20 +503,43 @@ func actionMkdir(c *cli.Context) error {
 		defer in.Close()
 	}
 
-	options := []gtree.Option{gtree.WithTargetDir(c.String("target-dir")), gtree.WithFileExtensions(c.StringSlice("extension"))}
+	oi, err := optionInput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	sortOpt, err := optionSort(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	ot, err := optionTemplateData(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	mode, err := optionMkdirMode(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	options := []gtree.Option{oi, ot, sortOpt, gtree.WithTargetDir(c.String("target-dir")), gtree.WithFileExtensions(c.StringSlice("extension")), gtree.WithMkdirMode(mode)}
 	if c.Bool("massive") {
 		options = append(options, gtree.WithMassive(context.Background()))
 	}
+	if c.Bool("rollback") {
+		options = append(options, gtree.WithMkdirRollback())
+	}
 
 	if c.Bool("dry-run") {
 		if err := outputWithValidation(in, options); err != nil {
-			return exitErrOutput(err)
+			return exitErrOutput(withInputPath(err, c.Path("file")))
 		}
 		return nil
 	}
 
-	if err := mkdir(in, options); err != nil {
-		return exitErrMkdir(err)
+	if err := mkdir(in, options, c.Bool("json")); err != nil {
+		return exitErrMkdir(withInputPath(err, c.Path("file")))
 	}
 
 	return nil
@@ -258,6 +549,46 @@ func isInputStdin(path string) bool {
 	return path == "" || path == "-"
 }
 
+func optionInput(c *cli.Context) (gtree.Option, error) {
+	switch c.String("input-format") {
+	case "tree":
+		return gtree.WithDecodeTree(), nil
+	case "json":
+		return gtree.WithDecodeJSON(), nil
+	case "yaml":
+		return gtree.WithDecodeYAML(), nil
+	case "toml":
+		return gtree.WithDecodeTOML(), nil
+	case "markdown", "":
+		return nil, nil
+	default:
+		return nil, errors.New(`specify either "markdown" or "tree" or "json" or "yaml" or "toml"`)
+	}
+}
+
+func optionSort(c *cli.Context) (gtree.Option, error) {
+	if len(c.String("sort")) == 0 {
+		return nil, nil
+	}
+
+	cmps := []func(a, b *gtree.WalkerNode) int{}
+	for _, order := range strings.Split(c.String("sort"), ",") {
+		switch strings.TrimSpace(order) {
+		case "name":
+			cmps = append(cmps, gtree.SortByName)
+		case "name-desc":
+			cmps = append(cmps, gtree.SortByNameDesc)
+		case "natural":
+			cmps = append(cmps, gtree.SortNatural)
+		case "dirs-first":
+			cmps = append(cmps, gtree.SortDirsFirst)
+		default:
+			return nil, errors.New(`specify either "name" or "name-desc" or "natural" or "dirs-first" for sort`)
+		}
+	}
+	return gtree.WithSort(cmps...), nil
+}
+
 func actionVerify(c *cli.Context) error {
 	var (
 		in  = os.Stdin
@@ -271,13 +602,149 @@ func actionVerify(c *cli.Context) error {
 		defer in.Close()
 	}
 
-	options := []gtree.Option{gtree.WithTargetDir(c.String("target-dir"))}
+	oi, err := optionInput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	ot, err := optionTemplateData(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	options := []gtree.Option{oi, ot, gtree.WithTargetDir(c.String("target-dir"))}
 	if c.Bool("strict") {
 		options = append(options, gtree.WithStrictVerify())
 	}
 
 	if err := verify(in, options); err != nil {
-		return exitErrVerify(err)
+		return exitErrVerify(withInputPath(err, c.Path("file")))
+	}
+	return nil
+}
+
+func actionLint(c *cli.Context) error {
+	var (
+		in  = os.Stdin
+		err error
+	)
+	if !isInputStdin(c.Path("file")) {
+		in, err = os.Open(c.Path("file"))
+		if err != nil {
+			return exitErrOpen(err)
+		}
+		defer in.Close()
+	}
+
+	if err := lint(in, c.Path("file")); err != nil {
+		return exitErrLint(err)
+	}
+	return nil
+}
+
+func actionScan(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return exitErrOpts(errors.New("specify only one directory"))
+	}
+	dir := "."
+	if c.NArg() == 1 {
+		dir = c.Args().First()
+	}
+
+	oo, err := optionScanOutput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	sortOpt, err := optionSort(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	options := []gtree.Option{oo, sortOpt, gtree.WithScanMaxDepth(c.Int("max-depth")), gtree.WithMaxChildren(c.Int("max-children")), gtree.WithFileExtensions(c.StringSlice("extension")), optionHTMLStyle(c)}
+	if c.Bool("all") {
+		options = append(options, gtree.WithScanHidden())
+	}
+	if c.Bool("compact") {
+		options = append(options, gtree.WithCompactChains())
+	}
+	if c.Bool("gitignore") {
+		options = append(options, gtree.WithScanGitignore())
+	}
+	if c.Bool("dirs-only") {
+		options = append(options, gtree.WithScanDirsOnly())
+	}
+
+	if err := scan(dir, options); err != nil {
+		return exitErrScan(err)
+	}
+	return nil
+}
+
+func actionDiff(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return exitErrOpts(errors.New("specify two markdown files"))
+	}
+	asJSON, err := optionDiffOutput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	oi, err := optionInput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	if err := diff(c.Args().Get(0), c.Args().Get(1), []gtree.Option{oi}, asJSON); err != nil {
+		return exitErrDiff(err)
+	}
+	return nil
+}
+
+func actionStats(c *cli.Context) error {
+	asJSON, err := optionStatsOutput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	oi, err := optionInput(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+
+	if err := stats(c.Path("file"), []gtree.Option{oi, gtree.WithFileExtensions(c.StringSlice("extension"))}, asJSON); err != nil {
+		return exitErrStats(err)
+	}
+	return nil
+}
+
+func actionFmt(c *cli.Context) error {
+	if c.Bool("write") && c.Bool("check") {
+		return exitErrOpts(errors.New("specify either --write or --check"))
+	}
+	if c.Bool("write") && c.NArg() == 0 {
+		return exitErrOpts(errors.New("cannot use --write with stdin"))
+	}
+	options, err := optionFmt(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	sortOpt, err := optionSort(c)
+	if err != nil {
+		return exitErrOpts(err)
+	}
+	options = append(options, sortOpt)
+
+	f := newFormatter(os.Stdout, options, c.Bool("write"), c.Bool("check"))
+	if c.NArg() == 0 {
+		if err := f.formatStdin(os.Stdin); err != nil {
+			return exitErrFmt(err)
+		}
+	}
+	for _, path := range c.Args().Slice() {
+		if err := f.formatFile(path); err != nil {
+			return exitErrFmt(err)
+		}
+	}
+
+	if err := f.result(); err != nil {
+		return exitNotFormatted(err)
 	}
 	return nil
 }
@@ -1,11 +1,64 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/ddddddO/gtree"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
 )
 
-func mkdir(in io.Reader, options []gtree.Option) error {
-	return gtree.Mkdir(in, options...)
+func mkdir(in io.Reader, options []gtree.Option, asJSON bool) error {
+	result, err := gtree.MkdirWithResult(in, options...)
+	if asJSON {
+		if jerr := printMkdirResultJSON(color.Output, result); jerr != nil {
+			return errors.Join(err, jerr)
+		}
+		return err
+	}
+	printMkdirResult(color.Output, result)
+	return err
+}
+
+var mkdirStatusColors = map[gtree.MkdirStatus]*color.Color{
+	gtree.MkdirCreatedDir:  color.New(color.FgHiGreen),
+	gtree.MkdirCreatedFile: color.New(color.FgHiGreen),
+	gtree.MkdirSkipped:     color.New(color.FgHiBlack),
+	gtree.MkdirOverwritten: color.New(color.FgHiYellow),
+	gtree.MkdirFailed:      color.New(color.FgHiRed),
+}
+
+func printMkdirResult(w io.Writer, result gtree.MkdirResult) {
+	for _, e := range result.Entries {
+		// 色のエスケープシーケンスを含めずに揃える
+		status := mkdirStatusColors[e.Status].Sprint(e.Status.String())
+		padding := len("created file") - len(e.Status.String())
+		if e.Err != nil {
+			fmt.Fprintf(w, "%s%*s  %s: %v\n", status, padding, "", e.Path, e.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s%*s  %s\n", status, padding, "", e.Path)
+	}
+}
+
+func printMkdirResultJSON(w io.Writer, result gtree.MkdirResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+func optionMkdirMode(c *cli.Context) (gtree.MkdirMode, error) {
+	switch {
+	case c.Bool("merge") && c.Bool("overwrite"):
+		return gtree.MkdirFail, errors.New("specify either --merge or --overwrite")
+	case c.Bool("merge"):
+		return gtree.MkdirMerge, nil
+	case c.Bool("overwrite"):
+		return gtree.MkdirOverwrite, nil
+	default:
+		return gtree.MkdirFail, nil
+	}
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
 	"time"
 
 	"github.com/ddddddO/gtree"
@@ -54,6 +57,17 @@ func outputContinuously(markdownPath string, options []gtree.Option) error {
 }
 
 func optionOutput(c *cli.Context) (gtree.Option, error) {
+	if c.IsSet("template") {
+		if c.IsSet("format") {
+			return nil, errors.New("specify either --format or --template")
+		}
+		t, err := texttemplate.ParseFiles(c.Path("template"))
+		if err != nil {
+			return nil, err
+		}
+		return gtree.WithTemplate(t), nil
+	}
+
 	switch c.String("format") {
 	case "json":
 		return gtree.WithEncodeJSON(), nil
@@ -61,9 +75,87 @@ func optionOutput(c *cli.Context) (gtree.Option, error) {
 		return gtree.WithEncodeYAML(), nil
 	case "toml":
 		return gtree.WithEncodeTOML(), nil
+	case "markdown":
+		return gtree.WithEncodeMarkdown(), nil
+	case "mermaid":
+		return gtree.WithEncodeMermaid(), nil
+	case "dot":
+		return gtree.WithEncodeDOT(), nil
+	case "html":
+		return gtree.WithEncodeHTML(), nil
 	case "":
 		return nil, nil
 	default:
-		return nil, errors.New(`specify either "json" or "yaml" or "toml"`)
+		return nil, errors.New(`specify either "json" or "yaml" or "toml" or "markdown" or "mermaid" or "dot" or "html"`)
+	}
+}
+
+func optionHTMLStyle(c *cli.Context) gtree.Option {
+	if !c.Bool("html-style") {
+		return nil
+	}
+	return gtree.WithHTMLStylesheet(gtree.DefaultHTMLStylesheet)
+}
+
+func optionBranch(c *cli.Context) ([]gtree.Option, error) {
+	name := c.String("style")
+	if len(name) == 0 {
+		name = "default"
+	}
+	style, ok := gtree.LookupBranchStyle(name)
+	if !ok {
+		return nil, fmt.Errorf("specify either %s for style", quoteJoin(gtree.BranchStyleNames(), " or "))
+	}
+
+	options := []gtree.Option{gtree.WithBranchStyle(name)}
+	if c.IsSet("branch-intermediate") {
+		directly, indirectly, found := strings.Cut(c.String("branch-intermediate"), ",")
+		if !found {
+			indirectly = style.IntermedialIndirectly
+		}
+		options = append(options, gtree.WithBranchFormatIntermedialNode(directly, indirectly))
+	}
+	if c.IsSet("branch-last") {
+		directly, indirectly, found := strings.Cut(c.String("branch-last"), ",")
+		if !found {
+			indirectly = style.LastIndirectly
+		}
+		options = append(options, gtree.WithBranchFormatLastNode(directly, indirectly))
+	}
+	return options, nil
+}
+
+// e.g. quoteJoin([]string{"a", "b"}, " or ") -> "a" or "b"
+func quoteJoin(ss []string, sep string) string {
+	quoted := make([]string, len(ss))
+	for i, s := range ss {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, sep)
+}
+
+func optionFilter(c *cli.Context) ([]gtree.Option, error) {
+	options := []gtree.Option{}
+	if patterns := c.StringSlice("match"); len(patterns) != 0 {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%w: %q", err, pattern)
+			}
+		}
+		options = append(options, gtree.WithFilter(func(wn *gtree.WalkerNode) bool {
+			for _, pattern := range patterns {
+				if ok, _ := path.Match(pattern, wn.Name()); ok {
+					return true
+				}
+			}
+			return false
+		}))
+	}
+	if patterns := c.StringSlice("exclude"); len(patterns) != 0 {
+		options = append(options, gtree.WithExclude(patterns...))
+	}
+	if c.Bool("highlight") {
+		options = append(options, gtree.WithHighlight())
 	}
+	return options, nil
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/ddddddO/gtree"
+	"github.com/urfave/cli/v2"
+)
+
+func scan(dir string, options []gtree.Option) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	// rootノード名をディレクトリ名にするため、親ディレクトリから辿る
+	fsys, name := os.DirFS(filepath.Dir(abs)), filepath.Base(abs)
+	if filepath.Dir(abs) == abs {
+		fsys, name = os.DirFS(abs), "."
+	}
+	root, err := gtree.FromDir(fsys, name, options...)
+	if err != nil {
+		return err
+	}
+	return gtree.OutputProgrammably(os.Stdout, root, options...)
+}
+
+func optionScanOutput(c *cli.Context) (gtree.Option, error) {
+	switch c.String("format") {
+	case "markdown", "":
+		return gtree.WithEncodeMarkdown(), nil
+	case "tree":
+		return nil, nil
+	case "json":
+		return gtree.WithEncodeJSON(), nil
+	case "yaml":
+		return gtree.WithEncodeYAML(), nil
+	case "toml":
+		return gtree.WithEncodeTOML(), nil
+	case "mermaid":
+		return gtree.WithEncodeMermaid(), nil
+	case "dot":
+		return gtree.WithEncodeDOT(), nil
+	case "html":
+		return gtree.WithEncodeHTML(), nil
+	default:
+		return nil, errors.New(`specify either "markdown" or "tree" or "json" or "yaml" or "toml" or "mermaid" or "dot" or "html"`)
+	}
+}
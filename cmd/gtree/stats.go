@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ddddddO/gtree"
+	"github.com/urfave/cli/v2"
+)
+
+func stats(path string, options []gtree.Option, asJSON bool) error {
+	roots, err := readRoots(path, options)
+	if err != nil {
+		return err
+	}
+
+	treeStats := make([]gtree.TreeStats, len(roots))
+	for i, root := range roots {
+		treeStats[i] = gtree.Stats(root, options...)
+	}
+
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(treeStats)
+	}
+	for i, s := range treeStats {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(s)
+	}
+	return nil
+}
+
+func optionStatsOutput(c *cli.Context) (bool, error) {
+	switch c.String("format") {
+	case "text", "":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, errors.New(`specify either "text" or "json"`)
+	}
+}
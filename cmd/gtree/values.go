@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ddddddO/gtree"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// --values のファイルを読み、--set で上書きした値を Markdown のテンプレートに渡す
+func optionTemplateData(c *cli.Context) (gtree.Option, error) {
+	sets := c.StringSlice("set")
+	valuesPath := c.Path("values")
+	if len(sets) == 0 && len(valuesPath) == 0 {
+		return nil, nil
+	}
+
+	data := map[string]any{}
+	if len(valuesPath) != 0 {
+		b, err := os.ReadFile(valuesPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(b, &data); err != nil {
+			return nil, fmt.Errorf("%s: %w", valuesPath, err)
+		}
+		if data == nil {
+			// 空のファイル
+			data = map[string]any{}
+		}
+	}
+
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok || len(strings.TrimSpace(key)) == 0 {
+			return nil, fmt.Errorf(`specify --set in the form of "key=value": %q`, set)
+		}
+		data[strings.TrimSpace(key)] = value
+	}
+	return gtree.WithTemplateData(data), nil
+}
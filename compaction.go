@@ -0,0 +1,54 @@
+//go:build !tinywasm
+
+package gtree
+
+// WithCompactChains returns function for rendering each chain of directories with only one child directory as a single node like "com/example/app" when outputting or walking tree.
+// A directory here is a node that has children, so a directory that has only one file is not merged with the file. The roots are not merged.
+// WalkerNode.Name returns the joined name and WalkerNode.Path returns the same path as without this option.
+// Mkdir and Verify ignore this option.
+func WithCompactChains() Option {
+	return func(c *config) {
+		c.compactChains = true
+	}
+}
+
+func (c *config) compaction() compaction {
+	return compaction{
+		enabled: c.compactChains,
+	}
+}
+
+// WithCompactChains で子が1つのディレクトリの連なりをまとめるか
+type compaction struct {
+	enabled bool
+}
+
+// 最後の兄弟かどうかや階層で枝が決まるため、枝の形成前にまとめる
+func (cp compaction) compact(root *Node) {
+	if !cp.enabled {
+		return
+	}
+	for _, child := range root.children {
+		cp.compactChain(child)
+	}
+}
+
+func (cp compaction) compactChain(current *Node) {
+	for len(current.children) == 1 && current.children[0].hasChild() {
+		only := current.children[0]
+		current.name = current.name + "/" + only.name
+		current.mergeComment(only.comment)
+		current.mergeMeta(only.meta)
+		current.highlighted = current.highlighted || only.highlighted
+
+		current.children = only.children
+		for _, child := range current.children {
+			child.setParent(current)
+			child.setHierarchy(current.hierarchy + 1)
+		}
+	}
+
+	for _, child := range current.children {
+		cp.compactChain(child)
+	}
+}
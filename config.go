@@ -1,6 +1,9 @@
 package gtree
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 type config struct {
 	lastNodeFormat        branchFormat
@@ -13,9 +16,32 @@ type config struct {
 	fileExtensions []string
 	targetDir      string
 	strictVerify   bool
+	decode         decode
+	scan           scanConfig
+	markdown       markdownStyle
+	htmlStylesheet string
+	sort           func(*fileConsiderer) nodeSorter
+	maxDepth       int
+	maxChildren    int
+	filter         func(*Node) bool
+	exclude        []string
+	highlight      bool
+	compactChains  bool
+	summary        bool
+	template       func(io.Writer, any) error
+	fileContent    func(path string) ([]byte, error)
+	templateData   any
+	mkdirMode      MkdirMode
+	mkdirReport    *MkdirReport
+	mkdirRollback  bool
+	mkdirResult    *MkdirResult
+	onCreate       func(path string, isFile bool)
+
+	// Option は error を返せないため、不正な指定はここに保持して各関数の開始時に返す
+	err error
 }
 
-func newConfig(options []Option) *config {
+func newConfig(options []Option) (*config, error) {
 	c := &config{
 		lastNodeFormat: branchFormat{
 			directly:   "└──",
@@ -28,6 +54,10 @@ func newConfig(options []Option) *config {
 		massive:   false,
 		encode:    encodeDefault,
 		targetDir: ".",
+		decode:    decodeMarkdown,
+		markdown: markdownStyle{
+			bullet: "-",
+		},
 	}
 	for _, opt := range options {
 		if opt == nil {
@@ -35,7 +65,10 @@ func newConfig(options []Option) *config {
 		}
 		opt(c)
 	}
-	return c
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c, nil
 }
 
 // Option is functional options pattern
@@ -84,12 +117,62 @@ func WithEncodeYAML() Option {
 }
 
 // WithEncodeTOML returns function for output toml format.
+// Multiple roots are output as an array of tables named root ([[root]]), because TOML has no separator of documents.
 func WithEncodeTOML() Option {
 	return func(c *config) {
 		c.encode = encodeTOML
 	}
 }
 
+// WithEncodeMarkdown returns function for output markdown format that can be input to gtree.
+// The names are escaped by markdown.Escape so that they are not read as annotations.
+func WithEncodeMarkdown() Option {
+	return func(c *config) {
+		c.encode = encodeMarkdown
+	}
+}
+
+// WithEncodeMermaid returns function for output mermaid flowchart (graph TD) format.
+// The rows summarizing the nodes hidden by WithMaxDepth / WithMaxChildren are rounded nodes linked with dotted lines.
+func WithEncodeMermaid() Option {
+	return func(c *config) {
+		c.encode = encodeMermaid
+	}
+}
+
+// WithEncodeDOT returns function for output DOT language format of Graphviz.
+// The rows summarizing the nodes hidden by WithMaxDepth / WithMaxChildren are dashed nodes linked with dashed edges.
+func WithEncodeDOT() Option {
+	return func(c *config) {
+		c.encode = encodeDOT
+	}
+}
+
+// WithEncodeHTML returns function for output a standalone HTML page that renders each root as nested <details>/<summary> elements.
+// Leaf nodes have the "file" class if they are considered as files by WithFileExtensions, otherwise the "dir" class.
+// The rows summarizing the nodes hidden by WithMaxDepth / WithMaxChildren have the "summary" class.
+func WithEncodeHTML() Option {
+	return func(c *config) {
+		c.encode = encodeHTML
+	}
+}
+
+// WithHTMLStylesheet returns function for embedding css in the page output by WithEncodeHTML.
+// DefaultHTMLStylesheet can be used as css.
+func WithHTMLStylesheet(css string) Option {
+	return func(c *config) {
+		c.htmlStylesheet = css
+	}
+}
+
+// DefaultHTMLStylesheet is a stylesheet for the page output by WithEncodeHTML.
+const DefaultHTMLStylesheet = `.gtree { font-family: monospace; line-height: 1.5; }
+.gtree details > details, .gtree details > div { margin-left: 1.5em; border-left: 1px solid #ccc; padding-left: 0.5em; }
+.gtree summary { cursor: pointer; }
+.gtree .dir { color: #1a7f37; }
+.gtree .file { color: #0969da; }
+.gtree .summary { color: #6e7781; font-style: italic; }`
+
 // WithDryRun returns function for dry run. Detects node that is invalid for directory generation.
 func WithDryRun() Option {
 	return func(c *config) {
@@ -98,6 +181,7 @@ func WithDryRun() Option {
 }
 
 // WithFileExtensions returns function for creating as a file instead of a directory.
+// A node without children that has a code block for its content in Markdown is also a file, whatever its extension is.
 func WithFileExtensions(extensions []string) Option {
 	return func(c *config) {
 		c.fileExtensions = extensions
@@ -117,3 +201,108 @@ func WithStrictVerify() Option {
 		c.strictVerify = true
 	}
 }
+
+type decode int
+
+const (
+	decodeMarkdown decode = iota
+	decodeTree
+	decodeJSON
+	decodeYAML
+	decodeTOML
+)
+
+// WithDecodeTree returns function for input tree format instead of Markdown.
+// The branches are read according to WithBranchFormatIntermedialNode / WithBranchFormatLastNode.
+// The rows of a code fence ("```" or "~~~") surrounding the tree are skipped.
+func WithDecodeTree() Option {
+	return func(c *config) {
+		c.decode = decodeTree
+	}
+}
+
+// WithDecodeJSON returns function for input json format instead of Markdown.
+// The input is the format output by WithEncodeJSON. A single object, a stream of objects or an array of objects can be input.
+func WithDecodeJSON() Option {
+	return func(c *config) {
+		c.decode = decodeJSON
+	}
+}
+
+// WithDecodeYAML returns function for input yaml format instead of Markdown.
+// The input is the format output by WithEncodeYAML. Multiple documents or a sequence of nodes can be input.
+func WithDecodeYAML() Option {
+	return func(c *config) {
+		c.decode = decodeYAML
+	}
+}
+
+// WithDecodeTOML returns function for input toml format instead of Markdown.
+// The input is the format output by WithEncodeTOML. Multiple roots are input as an array of tables named root ([[root]]).
+func WithDecodeTOML() Option {
+	return func(c *config) {
+		c.decode = decodeTOML
+	}
+}
+
+type scanConfig struct {
+	maxDepth  int
+	hidden    bool
+	gitignore bool
+	dirsOnly  bool
+}
+
+// WithScanMaxDepth returns function for limiting the depth of directories walked by FromDir function.
+// For example, 1 means only the entries directly under the root. Default is unlimited.
+func WithScanMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.scan.maxDepth = depth
+	}
+}
+
+// WithScanHidden returns function for including files and directories starting with "." in FromDir function.
+func WithScanHidden() Option {
+	return func(c *config) {
+		c.scan.hidden = true
+	}
+}
+
+// WithScanGitignore returns function for excluding paths ignored by .gitignore files in FromDir function.
+// The .git directory is also excluded.
+func WithScanGitignore() Option {
+	return func(c *config) {
+		c.scan.gitignore = true
+	}
+}
+
+// WithScanDirsOnly returns function for excluding files in FromDir function.
+func WithScanDirsOnly() Option {
+	return func(c *config) {
+		c.scan.dirsOnly = true
+	}
+}
+
+type markdownStyle struct {
+	bullet string
+	// 0 の場合はタブでインデントする
+	indent int
+}
+
+// WithMarkdownBullet returns function for specifying the bullet of each row output by WithEncodeMarkdown.
+// The bullet is one of "-", "*" or "+". Default is "-".
+func WithMarkdownBullet(bullet string) Option {
+	return func(c *config) {
+		c.markdown.bullet = bullet
+	}
+}
+
+// WithMarkdownIndent returns function for indenting the rows output by WithEncodeMarkdown with width spaces.
+// Default is indenting with a tab.
+func WithMarkdownIndent(width int) Option {
+	return func(c *config) {
+		c.markdown.indent = width
+	}
+}
+
+// 兄弟ノードを並び替える。nil の場合は入力順のまま
+type nodeSorter func(siblings []*Node)
@@ -0,0 +1,162 @@
+package gtree
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errCodeBlockPosition = errors.New("code block must be indented under a node")
+	errCodeBlockIndent   = errors.New("code block content is less indented than the opening fence")
+	errUnclosedCodeBlock = errors.New("unclosed code block")
+)
+
+// 関心事はノードの行の間にある、ファイルの内容の行の読み込み
+type contentReader interface {
+	setTarget(target *Node, row string)
+	read(row string, line int) (bool, error)
+	close() error
+}
+
+// コードブロックからファイルの内容を読むのは Markdown の入力のみ
+func newContentReaderFactory(decode decode) func() contentReader {
+	if decode != decodeMarkdown {
+		return func() contentReader { return newNopContentReader() }
+	}
+	return func() contentReader { return newContentReader() }
+}
+
+// ノードの行の下にインデントして書かれたコードブロックを、そのノードのファイルの内容として読む
+//
+//   - main.go
+//     ```go
+//     package main
+//     ```
+type codeBlockReader struct {
+	// 直前のノードとその行のインデント
+	target       *Node
+	targetIndent int
+
+	// 読んでいるコードブロックの開始のフェンス("```" や "~~~")。空ならコードブロックの外
+	fence     string
+	info      string
+	indent    string
+	lines     []string
+	startRow  string
+	startLine int
+}
+
+func newContentReader() *codeBlockReader {
+	return &codeBlockReader{}
+}
+
+// 生成したノードを、後に続くコードブロックの読み込み先とする。同名のノードをまとめた場合は、まとめた先のノードを渡す
+func (cr *codeBlockReader) setTarget(target *Node, row string) {
+	cr.target = target
+	cr.targetIndent = indentWidth(row)
+}
+
+// row をコードブロックの一部として読んだ場合 true を返す
+func (cr *codeBlockReader) read(row string, line int) (bool, error) {
+	if len(cr.fence) == 0 {
+		return cr.open(row, line)
+	}
+
+	trimmed := strings.TrimLeft(row, indentChars)
+	if strings.HasPrefix(trimmed, cr.fence) && len(strings.Trim(trimmed, cr.fence[:1]+indentChars)) == 0 {
+		cr.closeBlock()
+		return true, nil
+	}
+
+	switch {
+	case len(trimmed) == 0:
+		cr.lines = append(cr.lines, "")
+	case strings.HasPrefix(row, cr.indent):
+		cr.lines = append(cr.lines, row[len(cr.indent):])
+	default:
+		return true, newParseErrorAt(errCodeBlockIndent, row, line, indentWidth(row)+1)
+	}
+	return true, nil
+}
+
+func (cr *codeBlockReader) open(row string, line int) (bool, error) {
+	trimmed := strings.TrimLeft(row, indentChars)
+	fence := openingFence(trimmed)
+	if len(fence) == 0 {
+		return false, nil
+	}
+
+	indent := row[:len(row)-len(trimmed)]
+	cr.fence = fence
+	cr.info = strings.TrimSpace(trimmed[len(fence):])
+	cr.indent = indent
+	cr.lines = []string{}
+	cr.startRow = row
+	cr.startLine = line
+
+	// 位置が誤っていても、Lint で内容の行をノードとして報告しないようにコードブロックとしては読み進める
+	if cr.target == nil || len(indent) <= cr.targetIndent {
+		cr.target = nil
+		return true, newParseErrorAt(errCodeBlockPosition, row, line, len(indent)+1)
+	}
+	return true, nil
+}
+
+func (cr *codeBlockReader) closeBlock() {
+	content := []byte{}
+	if len(cr.lines) != 0 {
+		content = []byte(strings.Join(cr.lines, "\n") + "\n")
+	}
+	if cr.target != nil {
+		cr.target.mergeContent(content, cr.info)
+	}
+	cr.fence = ""
+	cr.lines = nil
+}
+
+// 入力の終わりに呼び、閉じられていないコードブロックがあればエラーを返す
+func (cr *codeBlockReader) close() error {
+	if len(cr.fence) == 0 {
+		return nil
+	}
+	return newParseErrorAt(errUnclosedCodeBlock, cr.startRow, cr.startLine, len(cr.indent)+1)
+}
+
+// 3つ以上の "`" か "~" の並びを返す。"`" のフェンスの後の情報文字列には "`" を含められない
+func openingFence(trimmed string) string {
+	if !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") {
+		return ""
+	}
+	c := trimmed[:1]
+	fence := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, c))]
+	if c == "`" && strings.Contains(trimmed[len(fence):], "`") {
+		return ""
+	}
+	return fence
+}
+
+func indentWidth(row string) int {
+	return len(row) - len(strings.TrimLeft(row, indentChars))
+}
+
+// 全ての行をノードの行として扱う
+type nopContentReader struct{}
+
+func newNopContentReader() *nopContentReader {
+	return &nopContentReader{}
+}
+
+func (*nopContentReader) setTarget(*Node, string) {}
+
+func (*nopContentReader) read(string, int) (bool, error) {
+	return false, nil
+}
+
+func (*nopContentReader) close() error {
+	return nil
+}
+
+var (
+	_ contentReader = (*codeBlockReader)(nil)
+	_ contentReader = (*nopContentReader)(nil)
+)
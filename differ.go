@@ -0,0 +1,495 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ChangeKind is the kind of Change.
+type ChangeKind int
+
+const (
+	// ChangeAdded means that the node exists only in the new tree.
+	ChangeAdded ChangeKind = iota + 1
+	// ChangeRemoved means that the node exists only in the old tree.
+	ChangeRemoved
+	// ChangeMoved means that the node has been moved under another parent with the same name.
+	ChangeMoved
+	// ChangeRenamed means that the node has been renamed under the same parent.
+	ChangeRenamed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeMoved:
+		return "moved"
+	case ChangeRenamed:
+		return "renamed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Change is a difference between two trees detected by Diff function.
+// Only the topmost node of a changed subtree is reported, e.g. the descendants of an added node are not reported.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// From is the path of the node in the old tree. It is empty for ChangeAdded.
+	From string `json:"from,omitempty"`
+	// To is the path of the node in the new tree. It is empty for ChangeRemoved.
+	To string `json:"to,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: %s", c.Kind, c.To)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: %s", c.Kind, c.From)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", c.Kind, c.From, c.To)
+	}
+}
+
+// Diff compares the tree a (old) with the tree b (new) and returns the changes in the order of the tree.
+// Nodes are compared by path. A removed node and an added node are reported as ChangeMoved if the name is unique among the removed and added nodes,
+// and as ChangeRenamed if they have the same parent and the same structure of descendants.
+// a or b can be nil, in which case the whole of the other tree is reported as removed or added.
+func Diff(a, b *Node) []Change {
+	_, changes := newDiffer().diff(a, b)
+	return changes
+}
+
+// OutputDiff outputs a unified tree of a (old) and b (new) to w.
+// Added nodes are marked with "+", removed nodes with "-", and moved or renamed nodes with "~". They are colored when w is a terminal.
+// The branch format can be changed with WithBranchFormatIntermedialNode / WithBranchFormatLastNode.
+func OutputDiff(w io.Writer, a, b *Node, options ...Option) error {
+	for _, root := range []*Node{a, b} {
+		if root != nil && !root.isRoot() {
+			return ErrNotRoot
+		}
+	}
+
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	d := newDiffer()
+	merged, _ := d.diff(a, b)
+	if merged == nil {
+		return nil
+	}
+	return newDiffSpreader(cfg.lastNodeFormat, cfg.intermedialNodeFormat).spread(w, merged, d.marks)
+}
+
+type differ struct {
+	a2b map[*Node]*Node
+	b2a map[*Node]*Node
+
+	// 対応するノードが見つかっていない部分木の先頭
+	removed []*Node
+	added   []*Node
+
+	changes map[*Node]*Change
+
+	// 統合したツリーのノードごとの差分
+	marks map[*Node]*diffMark
+}
+
+type diffMark struct {
+	kind   ChangeKind
+	change *Change // 部分木の先頭のみ
+}
+
+func newDiffer() *differ {
+	return &differ{
+		a2b:     map[*Node]*Node{},
+		b2a:     map[*Node]*Node{},
+		changes: map[*Node]*Change{},
+		marks:   map[*Node]*diffMark{},
+	}
+}
+
+func (d *differ) diff(a, b *Node) (*Node, []Change) {
+	switch {
+	case a == nil && b == nil:
+		return nil, []Change{}
+	case a == nil:
+		d.added = append(d.added, b)
+	case b == nil:
+		d.removed = append(d.removed, a)
+	default:
+		if a.name != b.name {
+			d.changes[b] = &Change{Kind: ChangeRenamed, From: a.Path(), To: b.Path()}
+		}
+		d.match(a, b)
+		d.pairUp()
+	}
+
+	for _, x := range d.removed {
+		d.changes[x] = &Change{Kind: ChangeRemoved, From: x.Path()}
+	}
+	for _, y := range d.added {
+		d.changes[y] = &Change{Kind: ChangeAdded, To: y.Path()}
+	}
+
+	var merged *Node
+	if b != nil {
+		merged = d.mergeNew(b, nil, 0)
+	} else {
+		merged = d.mergeRemoved(a, nil)
+	}
+	return merged, d.orderedChanges(merged)
+}
+
+func (d *differ) match(x, y *Node) {
+	d.a2b[x] = y
+	d.b2a[y] = x
+
+	for _, xc := range x.children {
+		if yc := y.findChildByText(xc.name); yc != nil {
+			d.match(xc, yc)
+			continue
+		}
+		d.removed = append(d.removed, xc)
+	}
+	for _, yc := range y.children {
+		if x.findChildByText(yc.name) == nil {
+			d.added = append(d.added, yc)
+		}
+	}
+}
+
+// 対応付けた部分木の子孫から新たな候補が見つかるため、対応付けられなくなるまで繰り返す。
+// 名前を変えたディレクトリの子孫が移動と判定されないように、名前の変更を先に対応付ける
+func (d *differ) pairUp() {
+	c := d.newPairCandidates()
+	for {
+		if x, y, ok := d.findRenamed(c); ok {
+			d.pairCandidate(c, x, y, ChangeRenamed)
+			continue
+		}
+		if x, y, ok := d.findMoved(c); ok {
+			d.pairCandidate(c, x, y, ChangeMoved)
+			continue
+		}
+		return
+	}
+}
+
+// 対応付けの候補。対応付けるたびに差分だけ更新する
+type pairCandidates struct {
+	signatures map[*Node]string
+
+	// 名前の変更の候補は、対応付いていない部分木の先頭。親の対応先と子孫の構造の組をキーとする
+	keys                   map[*Node]string
+	removedKeys, addedKeys map[string]int
+
+	// 移動の候補は、対応付いていない部分木の子孫も含む全てのノード
+	removedNames, addedNames map[string]int
+	addedByName              map[string][]*Node
+	unmatched                map[*Node]bool
+}
+
+func (d *differ) newPairCandidates() *pairCandidates {
+	c := &pairCandidates{
+		signatures:   map[*Node]string{},
+		keys:         map[*Node]string{},
+		removedKeys:  map[string]int{},
+		addedKeys:    map[string]int{},
+		removedNames: map[string]int{},
+		addedNames:   map[string]int{},
+		addedByName:  map[string][]*Node{},
+		unmatched:    map[*Node]bool{},
+	}
+	d.addCandidateRoots(c)
+
+	// 移動先が追加されたディレクトリの中である場合もあるため、対応付いていない部分木の子孫も候補にする
+	for _, x := range d.unmatched(d.removed, d.a2b) {
+		c.removedNames[x.name]++
+		c.unmatched[x] = true
+	}
+	for _, y := range d.unmatched(d.added, d.b2a) {
+		c.addedNames[y.name]++
+		c.addedByName[y.name] = append(c.addedByName[y.name], y)
+		c.unmatched[y] = true
+	}
+	return c
+}
+
+// 対応付けで新たに部分木の先頭となったノードを候補に加える
+func (d *differ) addCandidateRoots(c *pairCandidates) {
+	for _, x := range d.removed {
+		py, ok := d.a2b[x.parent]
+		if _, added := c.keys[x]; added || !ok {
+			continue
+		}
+		c.keys[x] = fmt.Sprintf("%p%s", py, c.signature(x))
+		c.removedKeys[c.keys[x]]++
+	}
+	for _, y := range d.added {
+		if _, added := c.keys[y]; added {
+			continue
+		}
+		c.keys[y] = fmt.Sprintf("%p%s", y.parent, c.signature(y))
+		c.addedKeys[c.keys[y]]++
+	}
+}
+
+func (d *differ) unmatched(roots []*Node, matched map[*Node]*Node) []*Node {
+	nodes := []*Node{}
+	var collect func(*Node)
+	collect = func(n *Node) {
+		if _, ok := matched[n]; ok {
+			return
+		}
+		nodes = append(nodes, n)
+		for _, child := range n.children {
+			collect(child)
+		}
+	}
+	for _, root := range roots {
+		collect(root)
+	}
+	return nodes
+}
+
+// 親と子孫の構造が同じノードが、それぞれ1つずつしかない場合のみ対応付ける
+func (d *differ) findRenamed(c *pairCandidates) (*Node, *Node, bool) {
+	for _, x := range d.removed {
+		k, ok := c.keys[x]
+		if !ok || c.removedKeys[k] != 1 || c.addedKeys[k] != 1 {
+			continue
+		}
+		i := slices.IndexFunc(d.added, func(y *Node) bool { return c.keys[y] == k })
+		return x, d.added[i], true
+	}
+	return nil, nil, false
+}
+
+// 同じ名前のノードが、それぞれ1つずつしかない場合のみ対応付ける。対応付いていない部分木の先頭の順に、深さ優先で探す
+func (d *differ) findMoved(c *pairCandidates) (*Node, *Node, bool) {
+	var find func(*Node) *Node
+	find = func(n *Node) *Node {
+		if !c.unmatched[n] {
+			return nil
+		}
+		if c.removedNames[n.name] == 1 && c.addedNames[n.name] == 1 {
+			return n
+		}
+		for _, child := range n.children {
+			if x := find(child); x != nil {
+				return x
+			}
+		}
+		return nil
+	}
+
+	for _, root := range d.removed {
+		if x := find(root); x != nil {
+			i := slices.IndexFunc(c.addedByName[x.name], func(y *Node) bool { return c.unmatched[y] })
+			return x, c.addedByName[x.name][i], true
+		}
+	}
+	return nil, nil, false
+}
+
+func (d *differ) pairCandidate(c *pairCandidates, x, y *Node, kind ChangeKind) {
+	if k, ok := c.keys[x]; ok {
+		c.removedKeys[k]--
+		delete(c.keys, x)
+	}
+	if k, ok := c.keys[y]; ok {
+		c.addedKeys[k]--
+		delete(c.keys, y)
+	}
+
+	d.pair(x, y, kind)
+
+	c.forgetMatched(x, d.a2b, c.removedNames)
+	c.forgetMatched(y, d.b2a, c.addedNames)
+	d.addCandidateRoots(c)
+}
+
+// 対応付けで対応先が決まったノードを候補から外す
+func (c *pairCandidates) forgetMatched(n *Node, matched map[*Node]*Node, names map[string]int) {
+	if _, ok := matched[n]; !ok {
+		return
+	}
+	if c.unmatched[n] {
+		names[n.name]--
+		delete(c.unmatched, n)
+	}
+	for _, child := range n.children {
+		c.forgetMatched(child, matched, names)
+	}
+}
+
+func (d *differ) pair(x, y *Node, kind ChangeKind) {
+	d.removed = slices.DeleteFunc(d.removed, func(n *Node) bool { return n == x })
+	d.added = slices.DeleteFunc(d.added, func(n *Node) bool { return n == y })
+	d.changes[y] = &Change{Kind: kind, From: x.Path(), To: y.Path()}
+	d.match(x, y)
+}
+
+// 子孫の名前と構造を表す文字列。兄弟の順番には依存しない
+func (c *pairCandidates) signature(n *Node) string {
+	if s, ok := c.signatures[n]; ok {
+		return s
+	}
+	children := make([]string, len(n.children))
+	for i, child := range n.children {
+		children[i] = strconv.Quote(child.name) + c.signature(child)
+	}
+	sort.Strings(children)
+	s := "(" + strings.Join(children, ",") + ")"
+	c.signatures[n] = s
+	return s
+}
+
+// 新しいツリーを基に、削除されたノードを元の位置に差し込んだツリーを作る
+func (d *differ) mergeNew(y, mergedParent *Node, inherited ChangeKind) *Node {
+	m := d.newMergedNode(y.name, mergedParent)
+	mark := &diffMark{kind: inherited}
+	if c, ok := d.changes[y]; ok {
+		mark.kind, mark.change = c.Kind, c
+	}
+	d.marks[m] = mark
+
+	// 移動したノードの子孫は、追加されたノードの中にあっても追加扱いにしない
+	childKind := ChangeKind(0)
+	if mark.kind == ChangeAdded {
+		childKind = ChangeAdded
+	}
+	for _, yc := range y.children {
+		m.addChild(d.mergeNew(yc, m, childKind))
+	}
+
+	x, ok := d.b2a[y]
+	if !ok {
+		return m
+	}
+	for i, xc := range x.children {
+		if c, ok := d.changes[xc]; !ok || c.Kind != ChangeRemoved {
+			continue
+		}
+		m.children = slices.Insert(m.children, min(i, len(m.children)), d.mergeRemoved(xc, m))
+	}
+	return m
+}
+
+func (d *differ) mergeRemoved(x, mergedParent *Node) *Node {
+	m := d.newMergedNode(x.name, mergedParent)
+	d.marks[m] = &diffMark{kind: ChangeRemoved, change: d.changes[x]}
+
+	for _, xc := range x.children {
+		if _, ok := d.a2b[xc]; ok {
+			continue // 他の場所へ移動した
+		}
+		m.addChild(d.mergeRemoved(xc, m))
+	}
+	return m
+}
+
+// 同名のノードを別々に残すため、Add ではなく直接生成する
+func (*differ) newMergedNode(name string, parent *Node) *Node {
+	if parent == nil {
+		return NewRoot(name)
+	}
+	return parent.newChild(name)
+}
+
+func (d *differ) orderedChanges(merged *Node) []Change {
+	changes := []Change{}
+	var collect func(*Node)
+	collect = func(m *Node) {
+		if c := d.marks[m].change; c != nil {
+			changes = append(changes, *c)
+		}
+		for _, child := range m.children {
+			collect(child)
+		}
+	}
+	collect(merged)
+	return changes
+}
+
+func newDiffSpreader(lastNodeFormat, intermedialNodeFormat branchFormat) *diffSpreader {
+	return &diffSpreader{
+		grower: newGrowerSimple(lastNodeFormat, intermedialNodeFormat, false, nil, truncation{}, pruning{}, compaction{}),
+		colors: map[ChangeKind]*color.Color{
+			ChangeAdded:   color.New(color.FgGreen),
+			ChangeRemoved: color.New(color.FgRed),
+			ChangeMoved:   color.New(color.FgYellow),
+			ChangeRenamed: color.New(color.FgYellow),
+		},
+	}
+}
+
+type diffSpreader struct {
+	grower growerSimple
+	colors map[ChangeKind]*color.Color
+}
+
+var diffMarkers = map[ChangeKind]string{
+	ChangeAdded:   "+",
+	ChangeRemoved: "-",
+	ChangeMoved:   "~",
+	ChangeRenamed: "~",
+}
+
+func (ds *diffSpreader) spread(w io.Writer, merged *Node, marks map[*Node]*diffMark) error {
+	if err := ds.grower.grow([]*Node{merged}); err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	if err := ds.spreadBranch(buf, merged, marks); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func (ds *diffSpreader) spreadBranch(w io.Writer, current *Node, marks map[*Node]*diffMark) error {
+	mark := marks[current]
+	row := current.name
+	if !current.isRoot() {
+		row = current.branch() + " " + current.name
+	}
+	if c := mark.change; c != nil && (c.Kind == ChangeMoved || c.Kind == ChangeRenamed) {
+		row += fmt.Sprintf(" (%s from %s)", c.Kind, c.From)
+	}
+
+	line := "  " + row
+	if marker, ok := diffMarkers[mark.kind]; ok {
+		line = ds.colors[mark.kind].Sprint(marker + " " + row)
+	}
+	if _, err := io.WriteString(w, line+"\n"); err != nil {
+		return err
+	}
+
+	for _, child := range current.children {
+		if err := ds.spreadBranch(w, child, marks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FromDir walks the directory root of fsys and returns the tree structure of it.
+// The name of the returned root node is the base name of root.
+// The returned node can be passed to OutputProgrammably / MkdirProgrammably / VerifyProgrammably / WalkProgrammably function.
+func FromDir(fsys fs.FS, root string, options ...Option) (*Node, error) {
+	if !fs.ValidPath(root) {
+		return nil, &fs.PathError{Op: "scan", Path: root, Err: fs.ErrInvalid}
+	}
+	fi, err := fs.Stat(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, &fs.PathError{Op: "scan", Path: root, Err: errNotDir}
+	}
+
+	cfg, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	return newDirScanner(fsys, cfg.scan).scan(root)
+}
+
+var errNotDir = errors.New("not a directory")
+
+type dirScanner struct {
+	fsys fs.FS
+	cfg  scanConfig
+}
+
+func newDirScanner(fsys fs.FS, cfg scanConfig) *dirScanner {
+	return &dirScanner{
+		fsys: fsys,
+		cfg:  cfg,
+	}
+}
+
+func (ds *dirScanner) scan(dir string) (*Node, error) {
+	root := NewRoot(path.Base(dir))
+	if err := ds.scanDir(root, dir, nil); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (ds *dirScanner) scanDir(parent *Node, dir string, ignores []*gitignore) error {
+	if ds.cfg.maxDepth > 0 && int(parent.hierarchy) > ds.cfg.maxDepth {
+		return nil
+	}
+
+	if ds.cfg.gitignore {
+		gi, err := ds.readGitignore(dir)
+		if err != nil {
+			return err
+		}
+		if gi != nil {
+			ignores = append(ignores[:len(ignores):len(ignores)], gi)
+		}
+	}
+
+	entries, err := fs.ReadDir(ds.fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if ds.skip(path.Join(dir, name), entry.IsDir(), ignores) {
+			continue
+		}
+
+		child := parent.Add(name)
+		if !entry.IsDir() {
+			continue
+		}
+		if err := ds.scanDir(child, path.Join(dir, name), ignores); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ds *dirScanner) skip(p string, isDir bool, ignores []*gitignore) bool {
+	name := path.Base(p)
+	if !ds.cfg.hidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	if ds.cfg.dirsOnly && !isDir {
+		return true
+	}
+	if ds.cfg.gitignore {
+		if name == ".git" && isDir {
+			return true
+		}
+		ignored := false
+		for _, gi := range ignores {
+			if matched, negated := gi.match(p, isDir); matched {
+				ignored = !negated
+			}
+		}
+		return ignored
+	}
+	return false
+}
+
+const gitignoreFile = ".gitignore"
+
+func (ds *dirScanner) readGitignore(dir string) (*gitignore, error) {
+	f, err := ds.fsys.Open(path.Join(dir, gitignoreFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	gi := &gitignore{base: dir}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if p := newGitignorePattern(sc.Text()); p != nil {
+			gi.patterns = append(gi.patterns, p)
+		}
+	}
+	return gi, sc.Err()
+}
+
+// 1つの .gitignore ファイル。パターンは base からの相対パスに対して評価する
+type gitignore struct {
+	base     string
+	patterns []*gitignorePattern
+}
+
+// 後に書かれたパターンが優先される
+func (gi *gitignore) match(p string, isDir bool) (matched, negated bool) {
+	rel := strings.TrimPrefix(p, gi.base+"/")
+	if gi.base == "." {
+		rel = p
+	}
+	for _, pattern := range gi.patterns {
+		if pattern.match(rel, isDir) {
+			matched, negated = true, pattern.negated
+		}
+	}
+	return matched, negated
+}
+
+type gitignorePattern struct {
+	re       *regexp.Regexp
+	negated  bool
+	dirOnly  bool
+	anchored bool
+}
+
+func newGitignorePattern(line string) *gitignorePattern {
+	line = strings.TrimRight(line, " ")
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	p := &gitignorePattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negated = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if len(line) == 0 {
+		return nil
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return nil
+	}
+	p.re = re
+	return p
+}
+
+func (p *gitignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.anchored {
+		return p.re.MatchString(rel)
+	}
+	return p.re.MatchString(path.Base(rel))
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**/") {
+				b.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -1,10 +1,13 @@
 package gtree
 
 var (
-	ExportErrEmptyText = errEmptyText
-	// TODO: fixme
-	// ExportErrIncorrectFormat = errIncorrectFormat
-	ExportErrIncorrectFormat = func(row string) error {
-		return &inputFormatError{row: row}
+	ExportErrEmptyText = func(row string, line int) error {
+		return newParseError(errEmptyText, row, line)
+	}
+	ExportErrIncorrectFormat = func(row string, line int) error {
+		return newParseError(errIncorrectFormat, row, line)
+	}
+	ExportErrIncorrectFormatAt = func(row string, line, column int) error {
+		return newParseErrorAt(errIncorrectFormat, row, line, column)
 	}
 )
@@ -18,6 +18,10 @@ func (fc *fileConsiderer) isFile(current *Node) bool {
 	if current.hasChild() {
 		return false
 	}
+	// Markdownで内容が書かれていればファイルとする
+	if current.content != nil {
+		return true
+	}
 
 	for _, e := range fc.extensions {
 		if strings.HasSuffix(current.name, e) {
@@ -26,3 +30,11 @@ func (fc *fileConsiderer) isFile(current *Node) bool {
 	}
 	return false
 }
+
+// WithFileExtensions が無い場合は、子を持たないノードをファイルとみなす
+func (fc *fileConsiderer) countsAsFile(current *Node) bool {
+	if len(fc.extensions) == 0 {
+		return !current.hasChild()
+	}
+	return fc.isFile(current)
+}
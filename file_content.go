@@ -0,0 +1,108 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WithFileContent returns function for writing the bytes returned by content to each file made by Mkdir / MkdirProgrammably.
+// content is called with the path of the file from the root node, whose separator is / in any OS execution environment,
+// and it is also called by dry run to show the number of bytes.
+// If content returns nil, the code block written under the file in Markdown is used, and an empty file is made if there is none.
+// Files are the nodes determined by WithFileExtensions or the nodes with a code block.
+func WithFileContent(content func(path string) ([]byte, error)) Option {
+	return func(c *config) {
+		c.fileContent = content
+	}
+}
+
+func (c *config) fileContents() fileContents {
+	return fileContents{
+		provide: c.fileContent,
+	}
+}
+
+// WithFileContent で指定された内容か、Markdownのコードブロックで書かれた内容
+type fileContents struct {
+	provide func(path string) ([]byte, error)
+}
+
+// nil の場合は空のファイルとする
+func (fc fileContents) content(current *Node) ([]byte, error) {
+	if fc.provide != nil {
+		content, err := fc.provide(current.path())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", current.path(), err)
+		}
+		if content != nil {
+			return content, nil
+		}
+	}
+	return current.content, nil
+}
+
+var errContentOfDirectory = errors.New("code block under a node with children")
+
+// 途中で失敗しても書きかけのファイルが見えないように、同じディレクトリの一時ファイルに書いてから置き換える。
+// 既存のファイルを置き換えるため、権限は置き換える前のファイルの perm を引き継ぐ
+func writeFileAtomically(path string, content []byte, perm fs.FileMode) error {
+	tmp, err := writeTempFile(path, content, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp) // 置き換えた後は存在しないため、失敗した場合のみ消える
+
+	// 一時ファイルの権限は umask を除いたものになるため、元のファイルの権限に揃える
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeFileAtomically と同様に一時ファイルから置き換える。
+// 内容の有無によらず os.Create と同じ権限(umask を除いた 0666)で作る
+func createFile(path string, content []byte) error {
+	tmp, err := writeTempFile(path, content, 0o666)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	return os.Rename(tmp, path)
+}
+
+// os.CreateTemp は 0600 で作るため、perm を指定できるように一時ファイルを作る。書き込みに失敗した場合は一時ファイルを消す
+func writeTempFile(path string, content []byte, perm fs.FileMode) (string, error) {
+	var (
+		f   *os.File
+		err error
+	)
+	for range 10000 {
+		tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+		f, err = os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,123 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"fmt"
+	"path"
+)
+
+// WithFilter returns function for keeping only the nodes for which match returns true and their ancestors when outputting or walking tree.
+// The other nodes, including the descendants of the matched nodes that do not match, are dropped. The roots are always kept.
+// match is called before the branches are formed, so WalkerNode.Branch and WalkerNode.Row return empty strings in it.
+// Mkdir and Verify ignore this option.
+func WithFilter(match func(*WalkerNode) bool) Option {
+	return func(c *config) {
+		if match == nil {
+			c.filter = nil
+			return
+		}
+		c.filter = func(n *Node) bool {
+			return match(&WalkerNode{origin: n})
+		}
+	}
+}
+
+// WithExclude returns function for dropping the nodes whose names match any of patterns and their descendants when outputting or walking tree.
+// The syntax of patterns is the same as path.Match. The roots are always kept.
+// If a pattern is malformed, the functions that take this option return path.ErrBadPattern.
+// Mkdir and Verify ignore this option.
+func WithExclude(patterns ...string) Option {
+	return func(c *config) {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				c.err = fmt.Errorf("%w: %q", err, pattern)
+				return
+			}
+		}
+		c.exclude = append(c.exclude, patterns...)
+	}
+}
+
+// WithHighlight returns function for coloring the names of the nodes matched by WithFilter in the tree output.
+func WithHighlight() Option {
+	return func(c *config) {
+		c.highlight = true
+	}
+}
+
+func (c *config) pruning() pruning {
+	return pruning{
+		match:     c.filter,
+		exclude:   c.exclude,
+		highlight: c.highlight,
+	}
+}
+
+// WithSort/WithMaxDepth/WithMaxChildren/WithFilter/WithExclude/WithCompactChains でツリーの形を変えるか
+func (c *config) reshapesTree() bool {
+	return c.sort != nil || c.truncation().enabled() || c.pruning().enabled() || c.compaction().enabled
+}
+
+// ディレクトリの生成や検証では、ツリーの形を変えると実際の構成と食い違うため入力のまま扱う
+func (c *config) keepTreeShape() {
+	c.maxDepth = 0
+	c.maxChildren = 0
+	c.filter = nil
+	c.exclude = nil
+	c.compactChains = false
+}
+
+// WithFilter/WithExclude で残すノードの基準
+type pruning struct {
+	match     func(*Node) bool
+	exclude   []string
+	highlight bool
+}
+
+func (p pruning) enabled() bool {
+	return p.match != nil || len(p.exclude) != 0
+}
+
+// 最後の兄弟かどうかで枝が決まるため、枝の形成前に刈り込む
+func (p pruning) prune(root *Node) {
+	if !p.enabled() {
+		return
+	}
+	// 判定で Path を使えるように、枝の形成前でもパスを設定しておく
+	p.pruneChildren(root)
+}
+
+func (p pruning) pruneChildren(current *Node) bool {
+	kept := current.children[:0]
+	for _, child := range current.children {
+		child.setPath(current.path(), child.name)
+		if p.isExcluded(child) {
+			continue
+		}
+		if p.pruneChildren(child) {
+			kept = append(kept, child)
+		}
+	}
+	clear(current.children[len(kept):])
+	current.children = kept
+
+	if p.match == nil {
+		return true
+	}
+	matched := p.match(current)
+	if matched && p.highlight {
+		current.highlighted = true
+	}
+	return matched || current.hasChild()
+}
+
+func (p pruning) isExcluded(current *Node) bool {
+	for _, pattern := range p.exclude {
+		// パターンは WithExclude で検証済み
+		if ok, _ := path.Match(pattern, current.name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -5,14 +5,19 @@ import (
 	"context"
 	"fmt"
 	"io"
-
-	md "github.com/ddddddO/gtree/markdown"
 )
 
-func split(ctx context.Context, r io.Reader) (<-chan string, <-chan error) {
+// Rootごとに分割されたMarkdown
+type block struct {
+	content string
+	// Markdown全体におけるブロック先頭行の行番号(1始まり)
+	startLine int
+}
+
+func split(ctx context.Context, r io.Reader, isRootBlockBeginning func(string) bool) (<-chan *block, <-chan error) {
 	sc := bufio.NewScanner(r)
-	blockc := make(chan string)
-	errc := make(chan error)
+	blockc := make(chan *block)
+	errc := make(chan error, 1)
 
 	go func() {
 		defer func() {
@@ -20,24 +25,28 @@ func split(ctx context.Context, r io.Reader) (<-chan string, <-chan error) {
 			close(errc)
 		}()
 
-		block := ""
+		var (
+			b    = &block{startLine: 1}
+			line = 0
+		)
 		for sc.Scan() {
 			select {
 			case <-ctx.Done():
 				return
 			default:
+				line++
 				l := sc.Text()
 				if isRootBlockBeginning(l) {
-					if len(block) != 0 {
+					if len(b.content) != 0 {
 						select {
 						case <-ctx.Done():
 							return
-						case blockc <- block:
+						case blockc <- b:
 						}
 					}
-					block = ""
+					b = &block{startLine: line}
 				}
-				block += fmt.Sprintln(l)
+				b.content += fmt.Sprintln(l)
 			}
 		}
 		if err := sc.Err(); err != nil {
@@ -47,17 +56,10 @@ func split(ctx context.Context, r io.Reader) (<-chan string, <-chan error) {
 		select {
 		case <-ctx.Done():
 			return
-		case blockc <- block: // 最後のRootブロック送出
+		case blockc <- b: // 最後のRootブロック送出
 			return
 		}
 	}()
 
 	return blockc, errc
 }
-
-func isRootBlockBeginning(l string) bool {
-	if len(l) == 0 {
-		return false
-	}
-	return md.IsSymbol(l[0:1])
-}
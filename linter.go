@@ -0,0 +1,137 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	md "github.com/ddddddO/gtree/markdown"
+)
+
+// Diagnostic is a problem in Markdown detected by Lint function.
+type Diagnostic struct {
+	// Line is the 1-based line number of the row.
+	Line int
+	// Column is the 1-based byte offset in the row where the problem was detected.
+	Column int
+	// Row is the raw row of the input.
+	Row string
+	// Message describes the problem.
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+// パースエラーがあっても止まらずに、最後の行まで検査する
+type linter struct {
+	parser      *md.Parser
+	diagnostics []Diagnostic
+
+	// ancestors[i] は直近に出現した hierarchy i+1 のノード
+	ancestors []*Node
+	lines     map[*Node]int
+	// 直前の行がパースエラーだった場合、その子ノードの親無しエラーは報告しない
+	afterParseErr bool
+}
+
+func newLinter() *linter {
+	return &linter{
+		parser: md.NewParser(),
+		lines:  map[*Node]int{},
+	}
+}
+
+func (l *linter) lint(r io.Reader) []Diagnostic {
+	sc := bufio.NewScanner(r)
+	contents := newContentReader()
+	line := 0
+	for sc.Scan() {
+		line++
+		row := sc.Text()
+		if ok, err := contents.read(row, line); ok {
+			l.reportContentErr(err)
+			continue
+		}
+		contents.setTarget(l.lintRow(row, line), row)
+	}
+	if err := sc.Err(); err != nil {
+		l.report(line+1, 1, "", err.Error())
+	}
+	l.reportContentErr(contents.close())
+	return l.diagnostics
+}
+
+// 行をノードとして扱えた場合は、そのノードを返す
+func (l *linter) lintRow(row string, line int) *Node {
+	markdown, err := l.parser.Parse(row)
+	if err != nil {
+		l.handleParseErr(err, row, line)
+		return nil
+	}
+
+	current := newNode(markdown.Text(), markdown.Hierarchy(), uint(line))
+	if current.isRoot() {
+		l.ancestors = []*Node{current}
+		l.lines[current] = line
+		l.afterParseErr = false
+		return current
+	}
+
+	column := errorColumn(errIncorrectFormat, row)
+	if len(l.ancestors) == 0 {
+		l.report(line, column, row, "no root node before this node")
+		return nil
+	}
+	if int(current.hierarchy) > len(l.ancestors)+1 {
+		if !l.afterParseErr {
+			l.report(line, column, row, fmt.Sprintf("no parent node at level %d", current.hierarchy-1))
+		}
+		return nil
+	}
+
+	parent := l.ancestors[current.hierarchy-2]
+	if child := parent.findChildByText(current.name); child != nil {
+		l.report(line, column, row, fmt.Sprintf("duplicate sibling %q is merged into line %d", current.name, l.lines[child]))
+		current = child
+	} else {
+		parent.addChild(current)
+		current.setParent(parent)
+		l.lines[current] = line
+	}
+	l.ancestors = append(l.ancestors[:current.hierarchy-1], current)
+	l.afterParseErr = false
+	return current
+}
+
+func (l *linter) reportContentErr(err error) {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		l.report(pe.Line, pe.Column, pe.Row, pe.Reason)
+	}
+}
+
+func (l *linter) handleParseErr(err error, row string, line int) {
+	switch {
+	case errors.Is(err, md.ErrBlankLine):
+		return
+	case errors.Is(err, md.ErrEmptyText):
+		l.report(line, errorColumn(errEmptyText, row), row, errEmptyText.Error())
+	default:
+		l.report(line, errorColumn(errIncorrectFormat, row), row, err.Error())
+	}
+	l.afterParseErr = true
+}
+
+func (l *linter) report(line, column int, row, message string) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		Line:    line,
+		Column:  column,
+		Row:     row,
+		Message: message,
+	})
+}
@@ -3,6 +3,8 @@ package markdown
 type Markdown struct {
 	hierarchy uint
 	text      string
+	comment   string
+	meta      map[string]string
 }
 
 func (m *Markdown) Hierarchy() uint {
@@ -13,6 +15,17 @@ func (m *Markdown) Text() string {
 	return m.text
 }
 
+// Meta returns the metadata written after the text, e.g. {"owner": "team-a"} for "- api.go {owner: team-a}".
+// It returns nil if there is no metadata.
+func (m *Markdown) Meta() map[string]string {
+	return m.meta
+}
+
+// Comment returns the annotation written after the text, e.g. "entrypoint" for "- main.go # entrypoint".
+func (m *Markdown) Comment() string {
+	return m.comment
+}
+
 const (
 	sharp = "#"
 
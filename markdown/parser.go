@@ -2,6 +2,9 @@ package markdown
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -14,6 +17,8 @@ type Parser struct {
 	mu          sync.RWMutex
 	spaces      int
 	sep         string
+	// spaces を決めた行のインデントの文字。Rootごとに sep を決め直しても、これと異なれば混在とする
+	spacesSep string
 }
 
 // TODO: 要リファクタ
@@ -29,6 +34,11 @@ var (
 	ErrBlankLine       = errors.New("blank line")
 	ErrEmptyText       = errors.New("empty text")
 	ErrIncorrectFormat = errors.New("incorrect input format")
+
+	// ErrMixedIndentation and ErrInconsistentIndentation describe ErrIncorrectFormat in detail.
+	// They can be compared with errors.Is(err, ErrIncorrectFormat).
+	ErrMixedIndentation        = fmt.Errorf("%w: mixed tab and space indentation", ErrIncorrectFormat)
+	ErrInconsistentIndentation = fmt.Errorf("%w: inconsistent indentation", ErrIncorrectFormat)
 )
 
 // TODO: 要リファクタ
@@ -46,7 +56,9 @@ func (p *Parser) Parse(row string) (*Markdown, error) {
 			return nil, ErrIncorrectFormat
 		}
 
-		text := strings.Trim(strings.TrimLeft(after, sharp), space)
+		text, comment := SplitComment(strings.Trim(strings.TrimLeft(after, sharp), space))
+		text, meta := SplitMeta(text)
+		text = Unescape(text)
 		if len(text) == 0 {
 			return nil, ErrEmptyText
 		}
@@ -54,6 +66,8 @@ func (p *Parser) Parse(row string) (*Markdown, error) {
 		return &Markdown{
 			hierarchy: rootHierarchyNum,
 			text:      text,
+			comment:   comment,
+			meta:      meta,
 		}, nil
 	}
 
@@ -65,7 +79,9 @@ func (p *Parser) Parse(row string) (*Markdown, error) {
 		return nil, err
 	}
 
-	text := strings.TrimPrefix(afterText, space)
+	text, comment := SplitComment(strings.TrimPrefix(afterText, space))
+	text, meta := SplitMeta(text)
+	text = Unescape(text)
 	if len(text) == 0 {
 		return nil, ErrEmptyText
 	}
@@ -73,9 +89,98 @@ func (p *Parser) Parse(row string) (*Markdown, error) {
 	return &Markdown{
 		hierarchy: p.calculateHierarchy(spaceCount),
 		text:      text,
+		comment:   comment,
+		meta:      meta,
 	}, nil
 }
 
+// " # " か " -- " 以降を注釈とする。"C#" や "a--b" のように空白で区切られていないものは注釈にしない
+var commentSeparator = regexp.MustCompile(`[ \t]+(#|--)([ \t]+|$)`)
+
+// SplitComment splits text into the node text and the annotation written after " # " or " -- ".
+// The separators escaped as `\#` or `\--` are not split, and remain in the node text until Unescape.
+// The separators in a double-quoted value of the metadata are not split either.
+func SplitComment(text string) (string, string) {
+	for _, loc := range commentSeparator.FindAllStringIndex(text, -1) {
+		if inMetaQuote(text, loc[0]) {
+			continue
+		}
+		return text[:loc[0]], strings.TrimSpace(text[loc[1]:])
+	}
+	return text, ""
+}
+
+// pos がメタデータ ("{" から "}" まで) のダブルクォートで囲まれた値の中にあるか
+func inMetaQuote(text string, pos int) bool {
+	inMeta, inQuote := false, false
+	for i := 0; i < pos; i++ {
+		c := text[i]
+		switch {
+		case inQuote:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inQuote = false
+			}
+		case inMeta:
+			if c == '"' {
+				inQuote = true
+			} else if c == '}' {
+				inMeta = false
+			}
+		case c == '{' && followsSpace(text, i):
+			inMeta = true
+		}
+	}
+	return inQuote
+}
+
+// "\" の後に続くと、その文字そのものとなる文字
+const escapable = `\#-{`
+
+// Unescape returns text with `\#`, `\-`, `\{` and `\\` replaced by "#", "-", "{" and `\`.
+// They are written in the node text to keep " # " and " -- " from being read as the annotation, and " {" as the metadata.
+// A `\` followed by any other character is left as it is.
+func Unescape(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if isEscape(text, i) {
+			i++
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// Escape returns text written so that Parser reads it back as the node text as it is. It is the reverse of Unescape.
+func Escape(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case isEscape(text, i):
+			b.WriteByte('\\')
+		case followsSpace(text, i) && (c == '#' || c == '{' || strings.HasPrefix(text[i:], "--")):
+			// 注釈の区切りやメタデータの始まりにならないように、空白の後の "#" "{" "--" をエスケープする
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isEscape(text string, i int) bool {
+	return text[i] == '\\' && i+1 < len(text) && strings.IndexByte(escapable, text[i+1]) >= 0
+}
+
+func followsSpace(text string, i int) bool {
+	return i > 0 && (text[i-1] == ' ' || text[i-1] == '\t')
+}
+
 func (*Parser) isBlank(row string) bool {
 	r := strings.TrimSpace(row)
 	return len(r) == 0
@@ -88,7 +193,9 @@ func (p *Parser) separateRow(row string) (int, string, error) {
 	for _, symbol := range listSymbols {
 		before, after, found := strings.Cut(row, symbol)
 		if !found {
-			err = ErrIncorrectFormat
+			if err == nil {
+				err = ErrIncorrectFormat
+			}
 			continue
 		}
 
@@ -99,7 +206,9 @@ func (p *Parser) separateRow(row string) (int, string, error) {
 					p.sep = sep
 				}
 			} else {
-				err = ErrIncorrectFormat
+				if err == nil {
+					err = ErrIncorrectFormat
+				}
 				continue
 			}
 		} else {
@@ -108,7 +217,11 @@ func (p *Parser) separateRow(row string) (int, string, error) {
 
 		spaceCount := strings.Count(before, p.sep)
 		if p.sep != "" && spaceCount != len(before) {
-			err = ErrIncorrectFormat
+			if strings.Trim(before, space+tab) == "" {
+				err = ErrMixedIndentation
+			} else if err == nil {
+				err = ErrIncorrectFormat
+			}
 			continue
 		}
 
@@ -120,6 +233,11 @@ func (p *Parser) separateRow(row string) (int, string, error) {
 		// Rootの次行のスペース数を一度だけ取得し、それをMarkdown全体のパースで利用する
 		if spaceCount > 0 && p.spaces == 0 {
 			p.spaces = spaceCount
+			p.spacesSep = p.sep
+		}
+		if spaceCount > 0 && p.sep != p.spacesSep {
+			err = ErrMixedIndentation
+			continue
 		}
 
 		if e := p.validateSpaces(spaceCount); e != nil {
@@ -138,7 +256,7 @@ func (p *Parser) validateSpaces(spaceCount int) error {
 		return nil
 	}
 	if spaceCount%p.spaces != 0 {
-		return ErrIncorrectFormat
+		return ErrInconsistentIndentation
 	}
 	return nil
 }
@@ -156,3 +274,78 @@ func (p *Parser) calculateHierarchy(spaceCount int) uint {
 	}
 	return hierarchy
 }
+
+// SplitMeta splits text into the node text and the metadata written at the end as "{key: value, key: value}".
+// A value containing "," or "}", or " # " or " -- " of the annotation, can be written as a double-quoted Go string literal.
+// The metadata escaped as `\{` is not split, and remains in the node text until Unescape.
+// If the end of text is not in this form, text is returned as it is with nil.
+func SplitMeta(text string) (string, map[string]string) {
+	if !strings.HasSuffix(text, "}") {
+		return text, nil
+	}
+	// 名前の途中の "{" と区別するため、空白の後の "{" から始まるものだけをメタデータとする
+	for i := strings.LastIndex(text, "{"); i > 0; i = strings.LastIndex(text[:i], "{") {
+		if text[i-1] != ' ' && text[i-1] != '\t' {
+			continue
+		}
+		if meta, err := parseMeta(text[i+1 : len(text)-1]); err == nil {
+			return strings.TrimRight(text[:i], space+tab), meta
+		}
+	}
+	return text, nil
+}
+
+var errInvalidMeta = errors.New("invalid metadata")
+
+func parseMeta(s string) (map[string]string, error) {
+	meta := map[string]string{}
+	for rest := strings.TrimSpace(s); len(rest) != 0; {
+		key, after, found := strings.Cut(rest, ":")
+		key = strings.TrimSpace(key)
+		if !found || len(key) == 0 || strings.ContainsAny(key, "{},\"") {
+			return nil, errInvalidMeta
+		}
+
+		value, after, err := cutMetaValue(strings.TrimLeft(after, space+tab))
+		if err != nil {
+			return nil, err
+		}
+		meta[key] = value
+
+		rest = strings.TrimLeft(after, space+tab)
+		if len(rest) == 0 {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, errInvalidMeta
+		}
+		rest = strings.TrimLeft(rest[1:], space+tab)
+	}
+	if len(meta) == 0 {
+		return nil, errInvalidMeta
+	}
+	return meta, nil
+}
+
+func cutMetaValue(s string) (string, string, error) {
+	if strings.HasPrefix(s, `"`) {
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", "", errInvalidMeta
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return "", "", errInvalidMeta
+		}
+		return value, s[len(quoted):], nil
+	}
+
+	end := strings.IndexAny(s, ",{}")
+	if end < 0 {
+		end = len(s)
+	}
+	if end < len(s) && s[end] != ',' {
+		return "", "", errInvalidMeta
+	}
+	return strings.TrimRight(s[:end], space+tab), s[end:], nil
+}
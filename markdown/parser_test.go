@@ -1,6 +1,8 @@
 package markdown
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -81,6 +83,70 @@ func TestParser_ParseTab(t *testing.T) {
 			},
 			nil,
 		},
+		"comment included": {
+			[]string{
+				"# root # sharp root",
+				"- main.go # entrypoint",
+				"	- go.mod   --   module definition",
+				"	- C# #",
+				"	- a--b # x # y",
+			},
+			[]*Markdown{
+				{hierarchy: 1, text: "root", comment: "sharp root"},
+				{hierarchy: 2, text: "main.go", comment: "entrypoint"},
+				{hierarchy: 3, text: "go.mod", comment: "module definition"},
+				{hierarchy: 3, text: "C#"},
+				{hierarchy: 3, text: "a--b", comment: "x # y"},
+			},
+			nil,
+		},
+		"escaped comment separator": {
+			[]string{
+				`- a \-- b`,
+				`	- C \# notes # comment`,
+				`	- back\\#slash`,
+				`	- a\b`,
+			},
+			[]*Markdown{
+				{hierarchy: 1, text: "a -- b"},
+				{hierarchy: 2, text: "C # notes", comment: "comment"},
+				{hierarchy: 2, text: `back\#slash`},
+				{hierarchy: 2, text: `a\b`},
+			},
+			nil,
+		},
+		"meta included": {
+			[]string{
+				"- api.go {owner: team-a} # handlers",
+				"	- b {owner: team-b, url: https://example.com/b?x=1}",
+				`	- c {note: "a, {b}"}`,
+				"	- d{x: y}",
+				"	- e {not meta}",
+				"	- f {x: y} {z: w}",
+			},
+			[]*Markdown{
+				{hierarchy: 1, text: "api.go", comment: "handlers", meta: map[string]string{"owner": "team-a"}},
+				{hierarchy: 2, text: "b", meta: map[string]string{"owner": "team-b", "url": "https://example.com/b?x=1"}},
+				{hierarchy: 2, text: "c", meta: map[string]string{"note": "a, {b}"}},
+				{hierarchy: 2, text: "d{x: y}"},
+				{hierarchy: 2, text: "e {not meta}"},
+				{hierarchy: 2, text: "f {x: y}", meta: map[string]string{"z": "w"}},
+			},
+			nil,
+		},
+		"escaped meta": {
+			[]string{
+				`- n \{k: v}`,
+				`	- m {note: "a # b", range: "1 -- 2"} # comment`,
+				`	- q {note: "say \"hi # x\""} -- comment`,
+			},
+			[]*Markdown{
+				{hierarchy: 1, text: "n {k: v}"},
+				{hierarchy: 2, text: "m", comment: "comment", meta: map[string]string{"note": "a # b", "range": "1 -- 2"}},
+				{hierarchy: 2, text: "q", comment: "comment", meta: map[string]string{"note": `say "hi # x"`}},
+			},
+			nil,
+		},
 	}
 
 	for name, tt := range tests {
@@ -95,7 +161,7 @@ func TestParser_ParseTab(t *testing.T) {
 				if err != tt.wantErr {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", err, tt.wantErr)
 				}
-				if *ret != *tt.wants[i] {
+				if !reflect.DeepEqual(ret, tt.wants[i]) {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", ret, tt.wants[i])
 				}
 			}
@@ -188,7 +254,7 @@ func TestParser_ParseTwoSpaces(t *testing.T) {
 				if err != tt.wantErr {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", err, tt.wantErr)
 				}
-				if *ret != *tt.wants[i] {
+				if !reflect.DeepEqual(ret, tt.wants[i]) {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", ret, tt.wants[i])
 				}
 			}
@@ -281,10 +347,122 @@ func TestParser_ParseFourSpaces(t *testing.T) {
 				if err != tt.wantErr {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", err, tt.wantErr)
 				}
-				if *ret != *tt.wants[i] {
+				if !reflect.DeepEqual(ret, tt.wants[i]) {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", ret, tt.wants[i])
 				}
 			}
 		})
 	}
 }
+
+func TestParser_ParseErr(t *testing.T) {
+	tests := map[string]struct {
+		rows    []string
+		wantErr error
+	}{
+		"mixed tab and space": {
+			[]string{
+				"- a",
+				"	- b",
+				"	 - c",
+			},
+			ErrMixedIndentation,
+		},
+		"space in tab indentation": {
+			[]string{
+				"- a",
+				"	- b",
+				"  - c",
+			},
+			ErrMixedIndentation,
+		},
+		"tab and space in other roots": {
+			[]string{
+				"- a",
+				"	- b",
+				"- c",
+				"  - d",
+			},
+			ErrMixedIndentation,
+		},
+		"inconsistent indentation": {
+			[]string{
+				"- a",
+				"    - b",
+				"      - c",
+			},
+			ErrInconsistentIndentation,
+		},
+		"text before symbol": {
+			[]string{
+				"- a",
+				"	- b",
+				"	xx- c",
+			},
+			ErrIncorrectFormat,
+		},
+		"empty text": {
+			[]string{
+				"- a",
+				"	-",
+			},
+			ErrEmptyText,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		parser := NewParser()
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			for i := range tt.rows {
+				_, err = parser.Parse(tt.rows[i])
+			}
+			if err != tt.wantErr {
+				t.Errorf("\ngot: \n%v\nwant: \n%v", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrIncorrectFormat) && tt.wantErr != ErrEmptyText {
+				t.Errorf("\ngot: \n%v\nwant: \n%v", err, ErrIncorrectFormat)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	texts := []string{
+		"main.go",
+		"a -- b",
+		"a --",
+		"C # notes",
+		"C#",
+		"a\t#\tb",
+		`back\#slash`,
+		`a \-- b`,
+		`a\b`,
+		`a\`,
+		"#",
+		"--",
+		"n {k: v}",
+		"a {",
+		`x\{`,
+	}
+
+	for _, text := range texts {
+		text := text
+		t.Run(text, func(t *testing.T) {
+			t.Parallel()
+
+			row := "- " + Escape(text) + " # comment"
+			got, err := NewParser().Parse(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Text() != text || got.Comment() != "comment" {
+				t.Errorf("\ngot: \n%q %q\nwant: \n%q %q", got.Text(), got.Comment(), text, "comment")
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package gtree
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sync"
+)
+
+// MkdirMode is how Mkdir / MkdirProgrammably treat the directories and files that already exist.
+type MkdirMode int
+
+const (
+	// MkdirFail returns ErrExistPath without making anything if any root already exists. This is the default.
+	MkdirFail MkdirMode = iota
+	// MkdirSkip leaves the roots that already exist untouched and makes the other roots.
+	MkdirSkip
+	// MkdirMerge makes only the missing directories and files. Existing files are never truncated.
+	MkdirMerge
+	// MkdirOverwrite makes the missing directories and files, and rewrites the existing files with their contents.
+	MkdirOverwrite
+)
+
+// WithMkdirMode returns function for specifying how Mkdir / MkdirProgrammably treat the directories and files that already exist.
+// In any mode, a path that exists as a file where a directory should be made, or the reverse, is an error.
+func WithMkdirMode(mode MkdirMode) Option {
+	return func(c *config) {
+		switch mode {
+		case MkdirFail, MkdirSkip, MkdirMerge, MkdirOverwrite:
+			c.mkdirMode = mode
+		default:
+			c.err = fmt.Errorf("invalid mkdir mode: %d", mode)
+		}
+	}
+}
+
+// MkdirReport is the paths handled by Mkdir / MkdirProgrammably, grouped by MkdirResult.Report.
+// The paths are in the same form as MkdirEntry.Path. In massive mode, the order of the paths is not guaranteed.
+type MkdirReport struct {
+	// Created is the directories and files made.
+	Created []string `json:"created"`
+	// Present is the directories and files that already existed and were left untouched.
+	// For MkdirSkip, the roots that already existed are reported without their descendants.
+	Present []string `json:"present"`
+	// Overwritten is the existing files rewritten by MkdirOverwrite.
+	Overwritten []string `json:"overwritten"`
+}
+
+// WithMkdirReport returns function for storing the paths handled by Mkdir / MkdirProgrammably in report.
+// report is filled even if an error is returned, with the paths handled until then.
+// It is the same as MkdirResult.Report of the result returned by MkdirWithResult / MkdirProgrammablyWithResult.
+func WithMkdirReport(report *MkdirReport) Option {
+	return func(c *config) {
+		c.mkdirReport = report
+	}
+}
+
+func (c *config) mkdirPolicy() mkdirPolicy {
+	return mkdirPolicy{
+		mode:     c.mkdirMode,
+		report:   c.mkdirReport,
+		result:   c.mkdirResult,
+		onCreate: c.onCreate,
+		rollback: c.mkdirRollback,
+	}
+}
+
+var (
+	errExistAsFile      = errors.New("path already exists as a file")
+	errExistAsDirectory = errors.New("path already exists as a directory")
+)
+
+// 既に存在するパスの扱いと、その報告先
+type mkdirPolicy struct {
+	mode     MkdirMode
+	report   *MkdirReport
+	result   *MkdirResult
+	onCreate func(path string, isFile bool)
+	rollback bool
+}
+
+func (mp mkdirPolicy) skipsExistingRoot() bool {
+	return mp.mode == MkdirSkip
+}
+
+func (mp mkdirPolicy) overwritesFile() bool {
+	return mp.mode == MkdirOverwrite
+}
+
+// Massiveモードでは複数のワーカーから記録する
+type mkdirRecord struct {
+	mu       sync.Mutex
+	entries  []MkdirEntry
+	onCreate func(path string, isFile bool)
+
+	// 以下はロールバックのための記録
+	targetDirs []string
+	backups    []fileBackup
+}
+
+// 上書きする前のファイル
+type fileBackup struct {
+	path    string
+	content []byte
+	mode    fs.FileMode
+}
+
+func newMkdirRecord(onCreate func(path string, isFile bool)) *mkdirRecord {
+	return &mkdirRecord{
+		entries:  []MkdirEntry{},
+		onCreate: onCreate,
+	}
+}
+
+// WithOnCreate のコールバックは同時に呼ばないように、ロックしたまま呼ぶ
+func (mr *mkdirRecord) add(entry MkdirEntry) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.entries = append(mr.entries, entry)
+	if mr.onCreate != nil && entry.Status.isCreated() {
+		mr.onCreate(entry.Path, entry.Status == MkdirCreatedFile)
+	}
+}
+
+func (mr *mkdirRecord) createdDir(path string) {
+	mr.add(MkdirEntry{Path: path, Status: MkdirCreatedDir})
+}
+
+func (mr *mkdirRecord) createdFile(path string) {
+	mr.add(MkdirEntry{Path: path, Status: MkdirCreatedFile, IsFile: true})
+}
+
+func (mr *mkdirRecord) present(path string, isFile bool) {
+	mr.add(MkdirEntry{Path: path, Status: MkdirSkipped, IsFile: isFile})
+}
+
+func (mr *mkdirRecord) overwritten(path string) {
+	mr.add(MkdirEntry{Path: path, Status: MkdirOverwritten, IsFile: true})
+}
+
+func (mr *mkdirRecord) failed(path string, isFile bool, err error) {
+	mr.add(MkdirEntry{Path: path, Status: MkdirFailed, IsFile: isFile, Err: err})
+}
+
+// 作成先のディレクトリは報告しないが、ロールバックでは削除する
+func (mr *mkdirRecord) madeTargetDir(dir string) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.targetDirs = append(mr.targetDirs, dir)
+}
+
+func (mr *mkdirRecord) backup(b fileBackup) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.backups = append(mr.backups, b)
+}
+
+// 呼び出し側でロックする
+func (mr *mkdirRecord) created() []string {
+	created := []string{}
+	for _, e := range mr.entries {
+		if e.Status.isCreated() {
+			created = append(created, e.Path)
+		}
+	}
+	return created
+}
+
+func (mp mkdirPolicy) fill(mr *mkdirRecord) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	result := MkdirResult{Entries: slices.Clone(mr.entries)}
+	if mp.result != nil {
+		*mp.result = result
+	}
+	if mp.report != nil {
+		*mp.report = result.Report()
+	}
+}
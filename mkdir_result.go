@@ -0,0 +1,134 @@
+package gtree
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MkdirStatus is what Mkdir / MkdirProgrammably did for a path.
+type MkdirStatus int
+
+const (
+	// MkdirCreatedDir is a directory made.
+	MkdirCreatedDir MkdirStatus = iota + 1
+	// MkdirCreatedFile is a file made.
+	MkdirCreatedFile
+	// MkdirSkipped is a path that already existed and was left untouched.
+	MkdirSkipped
+	// MkdirOverwritten is an existing file rewritten by MkdirOverwrite.
+	MkdirOverwritten
+	// MkdirFailed is a path that could not be made.
+	MkdirFailed
+)
+
+func (s MkdirStatus) String() string {
+	switch s {
+	case MkdirCreatedDir:
+		return "created dir"
+	case MkdirCreatedFile:
+		return "created file"
+	case MkdirSkipped:
+		return "skipped"
+	case MkdirOverwritten:
+		return "overwritten"
+	case MkdirFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("MkdirStatus(%d)", int(s))
+	}
+}
+
+// MarshalText encodes the status like "created_dir" for JSON.
+func (s MkdirStatus) MarshalText() ([]byte, error) {
+	switch s {
+	case MkdirCreatedDir:
+		return []byte("created_dir"), nil
+	case MkdirCreatedFile:
+		return []byte("created_file"), nil
+	case MkdirSkipped, MkdirOverwritten, MkdirFailed:
+		return []byte(s.String()), nil
+	default:
+		return nil, fmt.Errorf("invalid mkdir status: %d", int(s))
+	}
+}
+
+func (s MkdirStatus) isCreated() bool {
+	return s == MkdirCreatedDir || s == MkdirCreatedFile
+}
+
+// MkdirEntry is the result of a path handled by Mkdir / MkdirProgrammably.
+// Path is the path of the directory or file from the root node, and the separator is / in any OS execution environment.
+// The other APIs of Mkdir / MkdirProgrammably report paths in the same form.
+type MkdirEntry struct {
+	Path   string
+	Status MkdirStatus
+	// IsFile reports whether the path is a file.
+	IsFile bool
+	// Err is the cause of MkdirFailed. It is nil for the other statuses.
+	Err error
+}
+
+// MarshalJSON encodes the entry with Err as a string.
+func (e MkdirEntry) MarshalJSON() ([]byte, error) {
+	entry := struct {
+		Path   string      `json:"path"`
+		Status MkdirStatus `json:"status"`
+		IsFile bool        `json:"is_file"`
+		Error  string      `json:"error,omitempty"`
+	}{
+		Path:   e.Path,
+		Status: e.Status,
+		IsFile: e.IsFile,
+	}
+	if e.Err != nil {
+		entry.Error = e.Err.Error()
+	}
+	return json.Marshal(entry)
+}
+
+// MkdirResult is the result of each path handled by MkdirWithResult / MkdirProgrammablyWithResult in the order of handling.
+// In massive mode, the entries of different roots may be interleaved, and the failure of every worker is included.
+// The roots not handled because of a failure of another root or a cancellation of the context are not included.
+type MkdirResult struct {
+	Entries []MkdirEntry `json:"entries"`
+}
+
+// Failed returns the entries of MkdirFailed.
+func (r MkdirResult) Failed() []MkdirEntry {
+	failed := []MkdirEntry{}
+	for _, e := range r.Entries {
+		if e.Status == MkdirFailed {
+			failed = append(failed, e)
+		}
+	}
+	return failed
+}
+
+// Report returns the paths of the entries grouped by status. It is what WithMkdirReport stores.
+func (r MkdirResult) Report() MkdirReport {
+	report := MkdirReport{
+		Created:     []string{},
+		Present:     []string{},
+		Overwritten: []string{},
+	}
+	for _, e := range r.Entries {
+		switch e.Status {
+		case MkdirCreatedDir, MkdirCreatedFile:
+			report.Created = append(report.Created, e.Path)
+		case MkdirSkipped:
+			report.Present = append(report.Present, e.Path)
+		case MkdirOverwritten:
+			report.Overwritten = append(report.Overwritten, e.Path)
+		}
+	}
+	return report
+}
+
+// WithOnCreate returns function for calling onCreate with each directory and file made by Mkdir / MkdirProgrammably.
+// path is in the same form as MkdirEntry.Path.
+// onCreate is not called concurrently even in massive mode.
+func WithOnCreate(onCreate func(path string, isFile bool)) Option {
+	return func(c *config) {
+		c.onCreate = onCreate
+	}
+}
@@ -0,0 +1,106 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WithMkdirRollback returns function for undoing Mkdir / MkdirProgrammably when it fails halfway,
+// including a cancellation or a timeout of the context in massive mode.
+// The directories and files made are removed in the reverse order of making them, and the files rewritten by MkdirOverwrite are restored.
+// The paths that existed before are left untouched. The error returned is *MkdirRollbackError.
+func WithMkdirRollback() Option {
+	return func(c *config) {
+		c.mkdirRollback = true
+	}
+}
+
+// MkdirRollbackError is returned by Mkdir / MkdirProgrammably with WithMkdirRollback when making directories and files fails.
+// It can be compared with errors.As, and errors.Is / errors.As also match the cause.
+type MkdirRollbackError struct {
+	// Err is the cause of the failure.
+	Err error
+	// Removed is the directories and files removed by the rollback, in the order of removing them.
+	// The paths are in the same form as MkdirEntry.Path.
+	Removed []string
+	// Restored is the files rewritten by MkdirOverwrite and restored by the rollback.
+	Restored []string
+	// RollbackErr is the errors occurred in the rollback. It is nil if everything made has been undone.
+	RollbackErr error
+}
+
+func (e *MkdirRollbackError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("%v: rollback failed: %v", e.Err, e.RollbackErr)
+	}
+	msg := fmt.Sprintf("%v: rolled back: removed %s", e.Err, plural(len(e.Removed), "path", "paths"))
+	if len(e.Restored) != 0 {
+		msg += fmt.Sprintf(", restored %s", plural(len(e.Restored), "file", "files"))
+	}
+	return msg
+}
+
+func (e *MkdirRollbackError) Unwrap() error {
+	return e.Err
+}
+
+// cause が nil でなければ、上書きしたファイルを戻し、作ったパスを作った順と逆に削除する
+func (dm *defaultMkdirerSimple) rollbackRecord(record *mkdirRecord, cause error) error {
+	if cause == nil || !dm.policy.rollback {
+		return cause
+	}
+
+	record.mu.Lock()
+	defer record.mu.Unlock()
+
+	rerr := &MkdirRollbackError{
+		Err:      cause,
+		Removed:  []string{},
+		Restored: []string{},
+	}
+	var errs []error
+	for i := len(record.backups) - 1; i >= 0; i-- {
+		b := record.backups[i]
+		if err := restoreFile(filepath.Join(dm.targetDir, b.path), b); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rerr.Restored = append(rerr.Restored, b.path)
+	}
+	created := record.created()
+	for i := len(created) - 1; i >= 0; i-- {
+		p := created[i]
+		if err := os.Remove(filepath.Join(dm.targetDir, p)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+			continue
+		}
+		rerr.Removed = append(rerr.Removed, p)
+	}
+	for i := len(record.targetDirs) - 1; i >= 0; i-- {
+		if err := os.Remove(record.targetDirs[i]); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	rerr.RollbackErr = errors.Join(errs...)
+	return rerr
+}
+
+func restoreFile(path string, b fileBackup) error {
+	return writeFileAtomically(path, b.content, b.mode)
+}
+
+func backupFile(path, reportPath string) (fileBackup, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileBackup{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fileBackup{}, err
+	}
+	return fileBackup{path: reportPath, content: content, mode: fi.Mode().Perm()}, nil
+}
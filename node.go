@@ -10,11 +10,27 @@ import (
 // Node is main struct for gtree.
 type Node struct {
 	name      string
+	comment   string
+	meta      map[string]any
 	hierarchy uint
 	index     uint
 	brnch     branch
 	parent    *Node
 	children  []*Node
+
+	// NewRoot で生成したツリーの index の採番器。子ノードの index はこの採番器から振る
+	idxCounter *counter
+
+	// WithMaxDepth/WithMaxChildren で隠した子孫の数
+	truncated int
+	// 隠したノードをまとめた集計ノードか
+	isSummary bool
+	// WithFilter に一致して WithHighlight で色を付けるか
+	highlighted bool
+	// Markdownのコードブロックで書かれたファイルの内容。nil なら内容の指定は無い
+	content []byte
+	// コードブロックのフェンスの後の "go" のような情報文字列
+	contentInfo string
 }
 
 type branch struct {
@@ -30,6 +46,39 @@ func newNode(name string, hierarchy, index uint) *Node {
 	}
 }
 
+// 同じ階層の同名のノードをまとめる時、注釈は先に書かれたものを優先する
+func (n *Node) mergeComment(comment string) {
+	if len(n.comment) == 0 {
+		n.comment = comment
+	}
+}
+
+// 同じ階層の同名のノードをまとめる時、同じキーのメタデータは後に書かれたものを優先する
+func (n *Node) mergeMeta(meta map[string]any) {
+	for k, v := range meta {
+		n.setMeta(k, v)
+	}
+}
+
+// 同じ階層の同名のノードをまとめる時、ファイルの内容は先に書かれたものを優先する
+func (n *Node) mergeContent(content []byte, info string) {
+	if n.content == nil {
+		n.content = content
+		n.contentInfo = info
+	}
+}
+
+func (n *Node) setMeta(key string, v any) {
+	if v == nil {
+		delete(n.meta, key)
+		return
+	}
+	if n.meta == nil {
+		n.meta = map[string]any{}
+	}
+	n.meta[key] = v
+}
+
 func (n *Node) setParent(parent *Node) {
 	n.parent = parent
 }
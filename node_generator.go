@@ -3,54 +3,136 @@ package gtree
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	md "github.com/ddddddO/gtree/markdown"
 )
 
-type nodeGenerator struct {
+// 関心事は入力の1行からのノード生成
+type nodeGenerator interface {
+	generate(row string, line int, idx uint) (*Node, error)
+	isRootBlockBeginning(row string) bool
+}
+
+func newNodeGenerator(decode decode, lastNodeFormat, intermedialNodeFormat branchFormat) nodeGenerator {
+	switch decode {
+	case decodeTree:
+		return newTreeNodeGenerator(lastNodeFormat, intermedialNodeFormat)
+	default:
+		return newMarkdownNodeGenerator()
+	}
+}
+
+type markdownNodeGenerator struct {
 	parser *md.Parser
 }
 
-func newNodeGenerator() *nodeGenerator {
-	return &nodeGenerator{
+func newMarkdownNodeGenerator() *markdownNodeGenerator {
+	return &markdownNodeGenerator{
 		parser: md.NewParser(),
 	}
 }
 
 var (
 	errEmptyText = errors.New("empty text")
+	// md.ErrMixedIndentation などの詳細なエラーも、errors.Is で比較できる
+	errIncorrectFormat = md.ErrIncorrectFormat
 )
 
-type inputFormatError struct {
-	// n uint64 // markdownの行数もあとで
-	row string
+// ParseError is returned when a row of Markdown cannot be converted into a node.
+// It can be compared with errors.As.
+type ParseError struct {
+	// Line is the 1-based line number of the row in the whole input.
+	Line int
+	// Column is the 1-based byte offset in the row where the problem was detected.
+	Column int
+	// Row is the raw row of the input.
+	Row string
+	// Reason describes what is wrong with the row.
+	Reason string
+
+	err error
+}
+
+func newParseError(err error, row string, line int) *ParseError {
+	return newParseErrorAt(err, row, line, errorColumn(err, row))
+}
+
+func newParseErrorAt(err error, row string, line, column int) *ParseError {
+	return &ParseError{
+		Line:   line,
+		Column: column,
+		Row:    row,
+		Reason: err.Error(),
+		err:    err,
+	}
+}
+
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %q", pe.Line, pe.Column, pe.Reason, pe.Row)
 }
 
-func (ie *inputFormatError) Error() string {
-	return fmt.Sprintf("incorrect input format: %s", ie.row)
+func (pe *ParseError) Unwrap() error {
+	return pe.err
 }
 
-func (ng *nodeGenerator) generate(row string, idx uint) (*Node, error) {
+const indentChars = " \t"
+
+// errorColumn estimates the position where the markdown parser gave up on the row.
+func errorColumn(err error, row string) int {
+	if err == errEmptyText {
+		// テキストが来るはずだった位置
+		return len(strings.TrimRight(row, indentChars)) + 1
+	}
+
+	indent := len(row) - len(strings.TrimLeft(row, indentChars))
+	for i := 1; i < indent; i++ {
+		if row[i] != row[0] {
+			// スペースとタブが混在している位置
+			return i + 1
+		}
+	}
+	return indent + 1
+}
+
+func (ng *markdownNodeGenerator) generate(row string, line int, idx uint) (*Node, error) {
 	markdown, err := ng.parser.Parse(row)
 	if err != nil {
-		return nil, ng.handleErr(err, row)
+		return nil, ng.handleErr(err, row, line)
 	}
 
-	return newNode(
+	node := newNode(
 		markdown.Text(),
 		markdown.Hierarchy(),
 		idx,
-	), nil
+	)
+	node.comment = markdown.Comment()
+	for k, v := range markdown.Meta() {
+		node.setMeta(k, v)
+	}
+	return node, nil
 }
 
-func (*nodeGenerator) handleErr(err error, row string) error {
-	switch err {
-	case md.ErrEmptyText:
-		return errEmptyText
-	case md.ErrIncorrectFormat:
-		return &inputFormatError{row: row}
-	case md.ErrBlankLine:
+func (*markdownNodeGenerator) handleErr(err error, row string, line int) error {
+	switch {
+	case errors.Is(err, md.ErrEmptyText):
+		return newParseError(errEmptyText, row, line)
+	case errors.Is(err, md.ErrIncorrectFormat):
+		// インデントの問題が見つかった場合は、その詳細を理由とする
+		return newParseError(err, row, line)
+	case errors.Is(err, md.ErrBlankLine):
 		return nil
 	}
 	return err
 }
+
+func (*markdownNodeGenerator) isRootBlockBeginning(row string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	return md.IsSymbol(row[0:1])
+}
+
+var (
+	_ nodeGenerator = (*markdownNodeGenerator)(nil)
+)
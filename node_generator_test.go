@@ -13,7 +13,10 @@ type want struct {
 	err       error
 }
 
-const fixedIndex uint = 1
+const (
+	fixedIndex uint = 1
+	fixedLine       = 1
+)
 
 func TestGenerateTab(t *testing.T) {
 	tests := map[string]struct {
@@ -23,25 +26,25 @@ func TestGenerateTab(t *testing.T) {
 		"root/hierarchy=1":                 {"- aaa bb", &want{name: "aaa bb", hierarchy: 1, index: fixedIndex, err: nil}},
 		"child/hierarchy=2":                {"	- aaa bb", &want{name: "aaa bb", hierarchy: 2, index: fixedIndex, err: nil}},
 		"child/hierarchy=2/tab on the way": {"	- aaa	bb", &want{name: "aaa	bb", hierarchy: 2, index: fixedIndex, err: nil}},
-		"invalid/hierarchy=0/prefix chars": {"xx- aaa bb", &want{err: &inputFormatError{row: "xx- aaa bb"}}},
-		"invalid/hierarchy=0/no hyphen":    {"xx aaa bb", &want{err: &inputFormatError{row: "xx aaa bb"}}},
+		"invalid/hierarchy=0/prefix chars": {"xx- aaa bb", &want{err: newParseError(errIncorrectFormat, "xx- aaa bb", fixedLine)}},
+		"invalid/hierarchy=0/no hyphen":    {"xx aaa bb", &want{err: newParseError(errIncorrectFormat, "xx aaa bb", fixedLine)}},
 		"invalid/hierarchy=0/tab only":     {"			", nil},
 	}
 
-	nodeGenerator := newNodeGenerator()
-	_, _ = nodeGenerator.generate("	- xxx", fixedIndex) // Parserのインデントスペース数を決めるために必要
+	nodeGenerator := newMarkdownNodeGenerator()
+	_, _ = nodeGenerator.generate("	- xxx", fixedLine, fixedIndex) // Parserのインデントスペース数を決めるために必要
 
 	for name, tt := range tests {
 		tt := tt
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			node, err := nodeGenerator.generate(tt.row, fixedIndex)
+			node, err := nodeGenerator.generate(tt.row, fixedLine, fixedIndex)
 			if node == nil && err == nil {
 				return
 			}
 			if tt.want.err != nil {
-				if e, ok := err.(*inputFormatError); !ok {
+				if e, ok := err.(*ParseError); !ok {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", e, tt.want.err)
 				}
 				return
@@ -67,26 +70,26 @@ func TestGenerateTwoSpaces(t *testing.T) {
 	}{
 		"root/hierarchy=1":                     {"- aaa bb", &want{name: "aaa bb", hierarchy: 1, index: fixedIndex, err: nil}},
 		"child/hierarchy=2":                    {"  - aaa bb", &want{name: "aaa bb", hierarchy: 2, index: fixedIndex, err: nil}},
-		"invalid/hierarchy=0/prefix odd space": {" - aaa bb", &want{err: &inputFormatError{row: " - aaa bb"}}},
-		"invalid/hierarchy=0/prefix chars":     {"xx- aaa bb", &want{err: &inputFormatError{row: "xx- aaa bb"}}},
-		"invalid/hierarchy=0/no hyphen":        {"xx aaa bb", &want{err: &inputFormatError{row: "xx aaa bb"}}},
+		"invalid/hierarchy=0/prefix odd space": {" - aaa bb", &want{err: newParseError(errIncorrectFormat, " - aaa bb", fixedLine)}},
+		"invalid/hierarchy=0/prefix chars":     {"xx- aaa bb", &want{err: newParseError(errIncorrectFormat, "xx- aaa bb", fixedLine)}},
+		"invalid/hierarchy=0/no hyphen":        {"xx aaa bb", &want{err: newParseError(errIncorrectFormat, "xx aaa bb", fixedLine)}},
 		"invalid/hierarchy=0/space only":       {"  ", nil},
 	}
 
-	nodeGenerator := newNodeGenerator()
-	_, _ = nodeGenerator.generate("  - xxx", fixedIndex) // Parserのインデントスペース数を決めるために必要
+	nodeGenerator := newMarkdownNodeGenerator()
+	_, _ = nodeGenerator.generate("  - xxx", fixedLine, fixedIndex) // Parserのインデントスペース数を決めるために必要
 
 	for name, tt := range tests {
 		tt := tt
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			node, err := nodeGenerator.generate(tt.row, fixedIndex)
+			node, err := nodeGenerator.generate(tt.row, fixedLine, fixedIndex)
 			if node == nil && err == nil {
 				return
 			}
 			if tt.want.err != nil {
-				if e, ok := err.(*inputFormatError); !ok {
+				if e, ok := err.(*ParseError); !ok {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", e, tt.want.err)
 				}
 				return
@@ -113,26 +116,26 @@ func TestGenerateFourSpaces(t *testing.T) {
 		"root/hierarchy=1":                     {"- aaa bb", &want{name: "aaa bb", hierarchy: 1, index: fixedIndex, err: nil}},
 		"child/hierarchy=2":                    {"    - aaa bb", &want{name: "aaa bb", hierarchy: 2, index: fixedIndex, err: nil}},
 		"child/hierarchy=3":                    {"        - aaa    bb", &want{name: "aaa    bb", hierarchy: 3, index: fixedIndex, err: nil}},
-		"invalid/hierarchy=0/prefix odd space": {" - aaa bb", &want{err: &inputFormatError{row: " - aaa bb"}}},
-		"invalid/hierarchy=0/prefix chars":     {"xx- aaa bb", &want{err: &inputFormatError{row: "xx- aaa bb"}}},
-		"invalid/hierarchy=0/no hyphen":        {"xx aaa bb", &want{err: &inputFormatError{row: "xx aaa bb"}}},
+		"invalid/hierarchy=0/prefix odd space": {" - aaa bb", &want{err: newParseError(errIncorrectFormat, " - aaa bb", fixedLine)}},
+		"invalid/hierarchy=0/prefix chars":     {"xx- aaa bb", &want{err: newParseError(errIncorrectFormat, "xx- aaa bb", fixedLine)}},
+		"invalid/hierarchy=0/no hyphen":        {"xx aaa bb", &want{err: newParseError(errIncorrectFormat, "xx aaa bb", fixedLine)}},
 		"invalid/hierarchy=0/space only":       {"    ", nil},
 	}
 
-	nodeGenerator := newNodeGenerator()
-	_, _ = nodeGenerator.generate("    - xxx", fixedIndex) // Parserのインデントスペース数を決めるために必要
+	nodeGenerator := newMarkdownNodeGenerator()
+	_, _ = nodeGenerator.generate("    - xxx", fixedLine, fixedIndex) // Parserのインデントスペース数を決めるために必要
 
 	for name, tt := range tests {
 		tt := tt
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			node, err := nodeGenerator.generate(tt.row, fixedIndex)
+			node, err := nodeGenerator.generate(tt.row, fixedLine, fixedIndex)
 			if node == nil && err == nil {
 				return
 			}
 			if tt.want.err != nil {
-				if e, ok := err.(*inputFormatError); !ok {
+				if e, ok := err.(*ParseError); !ok {
 					t.Errorf("\ngot: \n%v\nwant: \n%v", e, tt.want.err)
 				}
 				return
@@ -150,3 +153,43 @@ func TestGenerateFourSpaces(t *testing.T) {
 		})
 	}
 }
+
+func TestParseError(t *testing.T) {
+	tests := map[string]struct {
+		row     string
+		line    int
+		wantErr string
+		wantCol int
+	}{
+		"empty text":                 {"	-", 3, `3:3: empty text: "\t-"`, 3},
+		"empty text/sharp":           {"#", 1, `1:2: empty text: "#"`, 2},
+		"incorrect format/mixed sep": {"	 - b", 42, `42:2: incorrect input format: mixed tab and space indentation: "\t - b"`, 2},
+		"incorrect format/no symbol": {"	xx b", 7, `7:2: incorrect input format: "\txx b"`, 2},
+		"incorrect format/other sep": {"   - b", 5, `5:4: incorrect input format: mixed tab and space indentation: "   - b"`, 4},
+	}
+
+	nodeGenerator := newMarkdownNodeGenerator()
+	_, _ = nodeGenerator.generate("	- xxx", fixedLine, fixedIndex) // Parserのインデントを決めるために必要
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := nodeGenerator.generate(tt.row, tt.line, fixedIndex)
+			pe, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("\ngot: \n%v\nwant: \n%s", err, tt.wantErr)
+			}
+			if pe.Error() != tt.wantErr {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", pe.Error(), tt.wantErr)
+			}
+			if pe.Line != tt.line {
+				t.Errorf("\ngot: \n%d\nwant: \n%d", pe.Line, tt.line)
+			}
+			if pe.Column != tt.wantCol {
+				t.Errorf("\ngot: \n%d\nwant: \n%d", pe.Column, tt.wantCol)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package gtree
+
+import (
+	"regexp"
+	"strings"
+
+	md "github.com/ddddddO/gtree/markdown"
+)
+
+// 出力済みのtree(├── / └── / │)の1行からノードを生成する
+type treeNodeGenerator struct {
+	lastNodeFormat        branchFormat
+	intermedialNodeFormat branchFormat
+}
+
+func newTreeNodeGenerator(lastNodeFormat, intermedialNodeFormat branchFormat) *treeNodeGenerator {
+	return &treeNodeGenerator{
+		lastNodeFormat:        lastNodeFormat,
+		intermedialNodeFormat: intermedialNodeFormat,
+	}
+}
+
+const nbsp = "\u00a0"
+
+// tree コマンドや dry run の出力末尾にある集計行
+var treeSummaryRow = regexp.MustCompile(`^\d+ director(y|ies), \d+ files?$`)
+
+// 空行、集計行と、Markdown から貼り付けた tree を囲むフェンス("```" や "~~~text")の行はノードにしない
+func isTreeSkipRow(normalized string) bool {
+	return len(strings.TrimSpace(normalized)) == 0 ||
+		treeSummaryRow.MatchString(normalized) ||
+		len(openingFence(normalized)) != 0
+}
+
+func (tg *treeNodeGenerator) generate(row string, line int, idx uint) (*Node, error) {
+	// tree コマンドはロケールによって枝にノーブレークスペースを使う
+	normalized := strings.ReplaceAll(row, nbsp, " ")
+	if isTreeSkipRow(normalized) {
+		return nil, nil
+	}
+
+	hierarchy := rootHierarchyNum
+	rest := normalized
+	for {
+		if after, ok := tg.cutDirectly(rest); ok {
+			name, comment := md.SplitComment(strings.TrimPrefix(after, " "))
+			if len(name) == 0 {
+				return nil, newParseErrorAt(errEmptyText, row, line, len(normalized)+1)
+			}
+			node := newNode(name, hierarchy+1, idx)
+			node.comment = comment
+			return node, nil
+		}
+
+		after, ok := tg.cutIndirectly(rest)
+		if !ok {
+			break
+		}
+		hierarchy++
+		rest = after
+	}
+
+	if hierarchy != rootHierarchyNum {
+		// 縦線のみで終わっていて、ノードに繋がる枝が無い
+		return nil, newParseErrorAt(errIncorrectFormat, row, line, len(normalized)-len(rest)+1)
+	}
+	name, comment := md.SplitComment(row)
+	root := newNode(name, rootHierarchyNum, idx)
+	root.comment = comment
+	return root, nil
+}
+
+func (tg *treeNodeGenerator) cutDirectly(row string) (string, bool) {
+	if after, ok := cutPrefix(row, tg.intermedialNodeFormat.directly); ok {
+		return after, true
+	}
+	return cutPrefix(row, tg.lastNodeFormat.directly)
+}
+
+func (tg *treeNodeGenerator) cutIndirectly(row string) (string, bool) {
+	if after, ok := cutPrefix(row, tg.intermedialNodeFormat.indirectly); ok {
+		return after, true
+	}
+	return cutPrefix(row, tg.lastNodeFormat.indirectly)
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if len(prefix) == 0 {
+		return s, false
+	}
+	return strings.CutPrefix(s, prefix)
+}
+
+func (tg *treeNodeGenerator) isRootBlockBeginning(row string) bool {
+	normalized := strings.ReplaceAll(row, nbsp, " ")
+	if isTreeSkipRow(normalized) {
+		return false
+	}
+	if _, ok := tg.cutDirectly(normalized); ok {
+		return false
+	}
+	_, ok := tg.cutIndirectly(normalized)
+	return !ok
+}
+
+var (
+	_ nodeGenerator = (*treeNodeGenerator)(nil)
+)
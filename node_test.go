@@ -74,3 +74,24 @@ func TestNode_IsLastOfHierarchy(t *testing.T) {
 		})
 	}
 }
+
+func TestNode_Clone(t *testing.T) {
+	root := NewRoot("root")
+	file := root.Add("main.go")
+	file.mergeContent([]byte("package main\n"), "go")
+	root.Add("empty.go").mergeContent([]byte{}, "")
+
+	cloned := root.Clone()
+	got := cloned.children[0]
+	if string(got.content) != "package main\n" || got.contentInfo != "go" {
+		t.Errorf("\ngot: \n%q %q\nwant: \n%q %q", got.content, got.contentInfo, "package main\n", "go")
+	}
+	if got := cloned.children[1].content; got == nil || len(got) != 0 {
+		t.Errorf("\ngot: \n%#v\nwant: \n%#v", got, []byte{})
+	}
+
+	got.content[0] = 'P'
+	if file.content[0] != 'p' {
+		t.Error("modifying the content of the clone must not affect the original")
+	}
+}
@@ -11,32 +11,46 @@ import (
 )
 
 type treePipeline struct {
-	grower   growerPipeline
-	spreader spreaderPipeline
-	mkdirer  mkdirerPipeline
-	verifier verifierPipeline
-	walker   walkerPipeline
+	rootGenerator rootGeneratorPipeline
+	grower        growerPipeline
+	spreader      spreaderPipeline
+	mkdirer       mkdirerPipeline
+	verifier      verifierPipeline
+	walker        walkerPipeline
 }
 
 var _ tree = (*treePipeline)(nil)
 
 func newTreePipeline(cfg *config) tree {
-	growerFactory := func(lastNodeFormat, intermedialNodeFormat branchFormat, dryrun bool, encode encode) growerPipeline {
-		if encode != encodeDefault {
-			return newNopGrowerPipeline()
+	rootGeneratorFactory := func(decode decode, lastNodeFormat, intermedialNodeFormat branchFormat, renderer rowRenderer) rootGeneratorPipeline {
+		switch decode {
+		case decodeJSON, decodeYAML, decodeTOML:
+			return newFormattedRootGeneratorPipeline(decode)
+		default:
+			return newRootGeneratorPipeline(
+				newNodeGenerator(decode, lastNodeFormat, intermedialNodeFormat),
+				newContentReaderFactory(decode),
+				renderer,
+			)
 		}
-		return newGrowerPipeline(lastNodeFormat, intermedialNodeFormat, dryrun)
 	}
 
-	spreaderFactory := func(encode encode, dryrun bool, fileExtensions []string) spreaderPipeline {
+	growerFactory := func(lastNodeFormat, intermedialNodeFormat branchFormat, dryrun bool, encode encode, sort nodeSorter, truncation truncation, pruning pruning, compaction compaction) growerPipeline {
+		if !encode.formsBranches() {
+			return newNopGrowerPipeline(sort, truncation, pruning, compaction)
+		}
+		return newGrowerPipeline(lastNodeFormat, intermedialNodeFormat, dryrun, sort, truncation, pruning, compaction)
+	}
+
+	spreaderFactory := func(encode encode, dryrun bool, fileExtensions []string, fileContents fileContents, markdown markdownStyle, htmlStylesheet string, summarizer summarizer, template func(io.Writer, any) error) spreaderPipeline {
 		if dryrun {
-			return newColorizeSpreaderPipeline(fileExtensions)
+			return newColorizeSpreaderPipeline(fileExtensions, fileContents)
 		}
-		return newSpreaderPipeline(encode)
+		return newSpreaderPipeline(encode, markdown, fileExtensions, htmlStylesheet, summarizer, template)
 	}
 
-	mkdirerFactory := func(targetDir string, fileExtensions []string) mkdirerPipeline {
-		return newMkdirerPipeline(targetDir, fileExtensions)
+	mkdirerFactory := func(targetDir string, fileExtensions []string, fileContents fileContents, policy mkdirPolicy) mkdirerPipeline {
+		return newMkdirerPipeline(targetDir, fileExtensions, fileContents, policy)
 	}
 
 	verifierFactory := func(targetDir string, strict bool) verifierPipeline {
@@ -48,20 +62,37 @@ func newTreePipeline(cfg *config) tree {
 	}
 
 	return &treePipeline{
+		rootGenerator: rootGeneratorFactory(
+			cfg.decode,
+			cfg.lastNodeFormat,
+			cfg.intermedialNodeFormat,
+			cfg.rowRenderer(),
+		),
 		grower: growerFactory(
 			cfg.lastNodeFormat,
 			cfg.intermedialNodeFormat,
 			cfg.dryrun,
 			cfg.encode,
+			cfg.nodeSorter(),
+			cfg.truncation(),
+			cfg.pruning(),
+			cfg.compaction(),
 		),
 		spreader: spreaderFactory(
 			cfg.encode,
 			cfg.dryrun,
 			cfg.fileExtensions,
+			cfg.fileContents(),
+			cfg.markdown,
+			cfg.htmlStylesheet,
+			cfg.summarizer(),
+			cfg.template,
 		),
 		mkdirer: mkdirerFactory(
 			cfg.targetDir,
 			cfg.fileExtensions,
+			cfg.fileContents(),
+			cfg.mkdirPolicy(),
 		),
 		verifier: verifierFactory(
 			cfg.targetDir,
@@ -75,11 +106,10 @@ func (t *treePipeline) output(w io.Writer, r io.Reader, cfg *config) error {
 	ctx, cancel := context.WithCancel(cfg.ctx)
 	defer cancel()
 
-	splitStream, errcsl := split(ctx, r)
-	rootStream, errcr := newRootGeneratorPipeline().generate(ctx, splitStream)
+	rootStream, errcr := t.rootGenerator.generate(ctx, r)
 	growStream, errcg := t.grower.grow(ctx, rootStream)
 	errcs := t.spreader.spread(ctx, w, growStream)
-	return t.handlePipelineErr(ctx, errcsl, errcr, errcg, errcs)
+	return t.handlePipelineErr(ctx, errcr, errcg, errcs)
 }
 
 func (t *treePipeline) outputProgrammably(w io.Writer, root *Node, cfg *config) error {
@@ -100,11 +130,10 @@ func (t *treePipeline) mkdir(r io.Reader, cfg *config) error {
 	ctx, cancel := context.WithCancel(cfg.ctx)
 	defer cancel()
 
-	splitStream, errcsl := split(ctx, r)
-	rootStream, errcr := newRootGeneratorPipeline().generate(ctx, splitStream)
+	rootStream, errcr := t.rootGenerator.generate(ctx, r)
 	growStream, errcg := t.grower.grow(ctx, rootStream)
 	errcm := t.mkdirer.mkdir(ctx, growStream)
-	return t.handlePipelineErr(ctx, errcsl, errcr, errcg, errcm)
+	return t.waitMkdirer(cancel, errcm, t.handlePipelineErr(ctx, errcr, errcg, errcm))
 }
 
 func (t *treePipeline) mkdirProgrammably(root *Node, cfg *config) error {
@@ -126,7 +155,15 @@ func (t *treePipeline) mkdirProgrammably(root *Node, cfg *config) error {
 	}
 	// when detected no invalid node name, no output tree.
 	errcm := t.mkdirer.mkdir(ctx, growStream)
-	return t.handlePipelineErr(ctx, errcg, errcm)
+	return t.waitMkdirer(cancel, errcm, t.handlePipelineErr(ctx, errcg, errcm))
+}
+
+// エラーで中断した場合も、作成したパスの報告とロールバックのためにワーカーの終了を待つ
+func (t *treePipeline) waitMkdirer(cancel context.CancelFunc, errcm <-chan error, err error) error {
+	cancel()
+	for range errcm {
+	}
+	return t.mkdirer.rollback(err)
 }
 
 func (t *treePipeline) verify(r io.Reader, cfg *config) error {
@@ -134,11 +171,10 @@ func (t *treePipeline) verify(r io.Reader, cfg *config) error {
 	defer cancel()
 
 	t.grower.enableValidation()
-	splitStream, errcsl := split(ctx, r)
-	rootStream, errcr := newRootGeneratorPipeline().generate(ctx, splitStream)
+	rootStream, errcr := t.rootGenerator.generate(ctx, r)
 	growStream, errcg := t.grower.grow(ctx, rootStream)
 	errcv := t.verifier.verify(ctx, growStream)
-	return t.handlePipelineErr(ctx, errcsl, errcr, errcg, errcv)
+	return t.handlePipelineErr(ctx, errcr, errcg, errcv)
 }
 
 func (t *treePipeline) verifyProgrammably(root *Node, cfg *config) error {
@@ -162,11 +198,10 @@ func (t *treePipeline) walk(r io.Reader, callback func(*WalkerNode) error, cfg *
 	ctx, cancel := context.WithCancel(cfg.ctx)
 	defer cancel()
 
-	splitStream, errcsl := split(ctx, r)
-	rootStream, errcr := newRootGeneratorPipeline().generate(ctx, splitStream)
+	rootStream, errcr := t.rootGenerator.generate(ctx, r)
 	growStream, errcg := t.grower.grow(ctx, rootStream)
 	errcw := t.walker.walk(ctx, growStream, callback)
-	return t.handlePipelineErr(ctx, errcsl, errcr, errcg, errcw)
+	return t.handlePipelineErr(ctx, errcr, errcg, errcw)
 }
 
 func (t *treePipeline) walkProgrammably(root *Node, callback func(*WalkerNode) error, cfg *config) error {
@@ -183,6 +218,11 @@ func (t *treePipeline) walkProgrammably(root *Node, callback func(*WalkerNode) e
 	return t.handlePipelineErr(ctx, errcg, errcw)
 }
 
+// 関心事は入力からのRootの生成
+type rootGeneratorPipeline interface {
+	generate(context.Context, io.Reader) (<-chan *Node, <-chan error)
+}
+
 // 関心事は各ノードの枝の形成
 type growerPipeline interface {
 	grow(context.Context, <-chan *Node) (<-chan *Node, <-chan error)
@@ -198,6 +238,7 @@ type spreaderPipeline interface {
 // interfaceを使う必要はないが、growerPipeline/spreaderPipelineと合わせたいため
 type mkdirerPipeline interface {
 	mkdir(context.Context, <-chan *Node) <-chan error
+	rollback(cause error) error
 }
 
 // 関心事はディレクトリの検証
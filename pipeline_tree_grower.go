@@ -10,9 +10,13 @@ import (
 func newGrowerPipeline(
 	lastNodeFormat, intermedialNodeFormat branchFormat,
 	enabledValidation bool,
+	sort nodeSorter,
+	truncation truncation,
+	pruning pruning,
+	compaction compaction,
 ) growerPipeline {
 	return &defaultGrowerPipeline{
-		defaultGrowerSimple: newGrowerSimple(lastNodeFormat, intermedialNodeFormat, enabledValidation).(*defaultGrowerSimple),
+		defaultGrowerSimple: newGrowerSimple(lastNodeFormat, intermedialNodeFormat, enabledValidation, sort, truncation, pruning, compaction).(*defaultGrowerSimple),
 	}
 }
 
@@ -53,6 +57,8 @@ func (dg *defaultGrowerPipeline) worker(ctx context.Context, wg *sync.WaitGroup,
 			if !ok {
 				return
 			}
+			dg.pruning.prune(root)
+			dg.compaction.compact(root)
 			if err := dg.assemble(root); err != nil {
 				errc <- err
 				return
@@ -66,9 +72,9 @@ func (dg *defaultGrowerPipeline) worker(ctx context.Context, wg *sync.WaitGroup,
 	}
 }
 
-func newNopGrowerPipeline() growerPipeline {
+func newNopGrowerPipeline(sort nodeSorter, truncation truncation, pruning pruning, compaction compaction) growerPipeline {
 	return &nopGrowerPipeline{
-		nopGrowerSimple: newNopGrowerSimple().(*nopGrowerSimple),
+		nopGrowerSimple: newNopGrowerSimple(sort, truncation, pruning, compaction).(*nopGrowerSimple),
 	}
 }
 
@@ -76,7 +82,7 @@ type nopGrowerPipeline struct {
 	*nopGrowerSimple
 }
 
-func (*nopGrowerPipeline) grow(ctx context.Context, roots <-chan *Node) (<-chan *Node, <-chan error) {
+func (ng *nopGrowerPipeline) grow(ctx context.Context, roots <-chan *Node) (<-chan *Node, <-chan error) {
 	nodes := make(chan *Node)
 	errc := make(chan error, 1)
 
@@ -95,6 +101,9 @@ func (*nopGrowerPipeline) grow(ctx context.Context, roots <-chan *Node) (<-chan
 				if !ok {
 					break BREAK
 				}
+				ng.pruning.prune(root)
+				ng.compaction.compact(root)
+				ng.arrange(root)
 				select {
 				case nodes <- root:
 				case <-ctx.Done():
@@ -7,20 +7,22 @@ import (
 	"sync"
 )
 
-func newMkdirerPipeline(dir string, fileExtensions []string) mkdirerPipeline {
+func newMkdirerPipeline(dir string, fileExtensions []string, fileContents fileContents, policy mkdirPolicy) mkdirerPipeline {
 	return &defaultMkdirerPipeline{
-		defaultMkdirerSimple: newMkdirerSimple(dir, fileExtensions).(*defaultMkdirerSimple),
+		defaultMkdirerSimple: newMkdirerSimple(dir, fileExtensions, fileContents, policy).(*defaultMkdirerSimple),
 	}
 }
 
 type defaultMkdirerPipeline struct {
 	*defaultMkdirerSimple
+	record *mkdirRecord
 }
 
 const workerMkdirNum = 10
 
 func (dm *defaultMkdirerPipeline) mkdir(ctx context.Context, roots <-chan *Node) <-chan error {
 	errc := make(chan error, 1)
+	dm.record = newMkdirRecord(dm.policy.onCreate)
 
 	go func() {
 		defer close(errc)
@@ -28,15 +30,21 @@ func (dm *defaultMkdirerPipeline) mkdir(ctx context.Context, roots <-chan *Node)
 		wg := &sync.WaitGroup{}
 		for i := 0; i < workerMkdirNum; i++ {
 			wg.Add(1)
-			go dm.worker(ctx, wg, roots, errc)
+			go dm.worker(ctx, wg, roots, errc, dm.record)
 		}
 		wg.Wait()
+		dm.policy.fill(dm.record)
 	}()
 
 	return errc
 }
 
-func (dm *defaultMkdirerPipeline) worker(ctx context.Context, wg *sync.WaitGroup, roots <-chan *Node, errc chan<- error) {
+// 前段のエラーやコンテキストのキャンセルも対象とするため、全てのワーカーの終了後に呼ぶ
+func (dm *defaultMkdirerPipeline) rollback(cause error) error {
+	return dm.rollbackRecord(dm.record, cause)
+}
+
+func (dm *defaultMkdirerPipeline) worker(ctx context.Context, wg *sync.WaitGroup, roots <-chan *Node, errc chan<- error, record *mkdirRecord) {
 	defer wg.Done()
 	for {
 		select {
@@ -46,11 +54,11 @@ func (dm *defaultMkdirerPipeline) worker(ctx context.Context, wg *sync.WaitGroup
 			if !ok {
 				return
 			}
-			if dm.isExistRoot([]*Node{root}) {
+			if dm.policy.mode == MkdirFail && dm.failExistingRoots([]*Node{root}, record) {
 				errc <- ErrExistPath
 				return
 			}
-			if err := dm.makeDirectoriesAndFiles(root); err != nil {
+			if err := dm.makeRoot(root, record); err != nil {
 				errc <- err
 				return
 			}
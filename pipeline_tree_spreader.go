@@ -14,7 +14,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func newSpreaderPipeline(encode encode) spreaderPipeline {
+func newSpreaderPipeline(encode encode, markdown markdownStyle, fileExtensions []string, htmlStylesheet string, summarizer summarizer, template func(io.Writer, any) error) spreaderPipeline {
 	switch encode {
 	case encodeJSON:
 		return newJSONSpreaderPipeline()
@@ -22,9 +22,19 @@ func newSpreaderPipeline(encode encode) spreaderPipeline {
 		return newYAMLSpreaderPipeline()
 	case encodeTOML:
 		return newTOMLSpreaderPipeline()
+	case encodeMarkdown:
+		return newMarkdownSpreaderPipeline(markdown)
+	case encodeMermaid:
+		return newDocumentSpreaderPipeline(newGraphSpreaderSimple(&mermaidFormat{}))
+	case encodeDOT:
+		return newDocumentSpreaderPipeline(newGraphSpreaderSimple(&dotFormat{}))
+	case encodeHTML:
+		return newDocumentSpreaderPipeline(newHTMLSpreaderSimple(fileExtensions, htmlStylesheet))
+	case encodeTemplate:
+		return newDocumentSpreaderPipeline(newTemplateSpreaderSimple(template, fileExtensions))
 	default:
 		return &defaultSpreaderPipeline{
-			defaultSpreaderSimple: &defaultSpreaderSimple{},
+			defaultSpreaderSimple: &defaultSpreaderSimple{summarizer: summarizer},
 		}
 	}
 }
@@ -68,21 +78,22 @@ func (ds *defaultSpreaderPipeline) worker(ctx context.Context, wg *sync.WaitGrou
 			}
 
 			ds.Lock()
-			ds.spreadBranch(root)
+			ds.spreadRoot(root)
 			ds.Unlock()
 		}
 	}
 }
 
 type formattedSpreaderPipeline[T sitter] struct {
-	formattedRoot func(string) T
+	formattedRoot func(*Node) T
 	encode        func(io.Writer) func(any) error
+	rootsValue    func(T) any
 }
 
 func newJSONSpreaderPipeline() *formattedSpreaderPipeline[*jsonNode] {
 	return &formattedSpreaderPipeline[*jsonNode]{
-		formattedRoot: func(name string) *jsonNode {
-			return &jsonNode{Name: name}
+		formattedRoot: func(root *Node) *jsonNode {
+			return &jsonNode{Name: root.name, Comment: root.comment, Meta: root.meta, Truncated: root.truncated}
 		},
 		encode: func(w io.Writer) func(any) error {
 			return json.NewEncoder(w).Encode
@@ -92,8 +103,8 @@ func newJSONSpreaderPipeline() *formattedSpreaderPipeline[*jsonNode] {
 
 func newYAMLSpreaderPipeline() *formattedSpreaderPipeline[*yamlNode] {
 	return &formattedSpreaderPipeline[*yamlNode]{
-		formattedRoot: func(name string) *yamlNode {
-			return &yamlNode{Name: name}
+		formattedRoot: func(root *Node) *yamlNode {
+			return &yamlNode{Name: root.name, Comment: root.comment, Meta: root.meta, Truncated: root.truncated}
 		},
 		encode: func(w io.Writer) func(any) error {
 			return yaml.NewEncoder(w).Encode
@@ -103,12 +114,13 @@ func newYAMLSpreaderPipeline() *formattedSpreaderPipeline[*yamlNode] {
 
 func newTOMLSpreaderPipeline() *formattedSpreaderPipeline[*tomlNode] {
 	return &formattedSpreaderPipeline[*tomlNode]{
-		formattedRoot: func(name string) *tomlNode {
-			return &tomlNode{Name: name}
+		formattedRoot: func(root *Node) *tomlNode {
+			return &tomlNode{Name: root.name, Comment: root.comment, Meta: root.meta, Truncated: root.truncated}
 		},
 		encode: func(w io.Writer) func(any) error {
 			return toml.NewEncoder(w).Encode
 		},
+		rootsValue: tomlRootsValue,
 	}
 }
 
@@ -119,6 +131,15 @@ func (f *formattedSpreaderPipeline[T]) spread(ctx context.Context, w io.Writer,
 		defer close(errc)
 
 		encode := f.encode(w)
+		spread := func(root *Node, multiRoot bool) {
+			if err := encode(formattedValue(root, f.formattedRoot, f.rootsValue, multiRoot)); err != nil {
+				errc <- err
+			}
+		}
+
+		// rootsValue でまとめる形式では、2つ目のRootが来るまで最初のRootの出力を待つ
+		var first *Node
+		count := 0
 	BREAK:
 		for {
 			select {
@@ -128,19 +149,121 @@ func (f *formattedSpreaderPipeline[T]) spread(ctx context.Context, w io.Writer,
 				if !ok {
 					break BREAK
 				}
-				if err := encode(toFormattedNode(root, f.formattedRoot(root.name))); err != nil {
+				count++
+				switch {
+				case f.rootsValue == nil:
+					spread(root, false)
+				case count == 1:
+					first = root
+				case count == 2:
+					spread(first, true)
+					spread(root, true)
+				default:
+					spread(root, true)
+				}
+			}
+		}
+		if count == 1 && first != nil {
+			spread(first, false)
+		}
+	}()
+
+	return errc
+}
+
+func newMarkdownSpreaderPipeline(style markdownStyle) *markdownSpreaderPipeline {
+	return &markdownSpreaderPipeline{
+		markdownSpreaderSimple: newMarkdownSpreaderSimple(style),
+	}
+}
+
+type markdownSpreaderPipeline struct {
+	*markdownSpreaderSimple
+}
+
+func (ms *markdownSpreaderPipeline) spread(ctx context.Context, w io.Writer, roots <-chan *Node) <-chan error {
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+
+		bw := bufio.NewWriter(w)
+	BREAK:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case root, ok := <-roots:
+				if !ok {
+					break BREAK
+				}
+				if err := ms.spreadRoot(bw, root); err != nil {
 					errc <- err
+					return
 				}
 			}
 		}
+		if err := bw.Flush(); err != nil {
+			errc <- err
+		}
 	}()
 
 	return errc
 }
 
-func newColorizeSpreaderPipeline(fileExtensions []string) spreaderPipeline {
+func newDocumentSpreaderPipeline(ds documentSpreaderSimple) *documentSpreaderPipeline {
+	return &documentSpreaderPipeline{
+		documentSpreaderSimple: ds,
+	}
+}
+
+type documentSpreaderPipeline struct {
+	documentSpreaderSimple
+}
+
+func (ds *documentSpreaderPipeline) spread(ctx context.Context, w io.Writer, roots <-chan *Node) <-chan error {
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+
+		bw := bufio.NewWriter(w)
+		if _, err := bw.WriteString(ds.header()); err != nil {
+			errc <- err
+			return
+		}
+		rootIdx := 0
+	BREAK:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case root, ok := <-roots:
+				if !ok {
+					break BREAK
+				}
+				if err := ds.spreadRoot(bw, root, rootIdx); err != nil {
+					errc <- err
+					return
+				}
+				rootIdx++
+			}
+		}
+		if _, err := bw.WriteString(ds.footer()); err != nil {
+			errc <- err
+			return
+		}
+		if err := bw.Flush(); err != nil {
+			errc <- err
+		}
+	}()
+
+	return errc
+}
+
+func newColorizeSpreaderPipeline(fileExtensions []string, fileContents fileContents) spreaderPipeline {
 	return &colorizeSpreaderPipeline{
-		colorizeSpreaderSimple: newColorizeSpreaderSimple(fileExtensions).(*colorizeSpreaderSimple),
+		colorizeSpreaderSimple: newColorizeSpreaderSimple(fileExtensions, fileContents).(*colorizeSpreaderSimple),
 	}
 }
 
@@ -167,10 +290,15 @@ func (cs *colorizeSpreaderPipeline) spread(ctx context.Context, w io.Writer, roo
 				cs.fileCounter.reset()
 				cs.dirCounter.reset()
 
+				branch, err := cs.spreadBranch(root)
+				if err != nil {
+					errc <- err
+					return
+				}
 				if _, err := bw.WriteString(
 					fmt.Sprintf(
 						"%s\n%s\n",
-						cs.spreadBranch(root),
+						branch,
 						cs.summary()),
 				); err != nil {
 					errc <- err
@@ -190,5 +318,7 @@ func (cs *colorizeSpreaderPipeline) spread(ctx context.Context, w io.Writer, roo
 var (
 	_ spreaderPipeline = (*defaultSpreaderPipeline)(nil)
 	_ spreaderPipeline = (*formattedSpreaderPipeline[sitter])(nil)
+	_ spreaderPipeline = (*markdownSpreaderPipeline)(nil)
+	_ spreaderPipeline = (*documentSpreaderPipeline)(nil)
 	_ spreaderPipeline = (*colorizeSpreaderPipeline)(nil)
 )
@@ -10,28 +10,44 @@ import (
 	"sync"
 )
 
-type rootGeneratorSimple struct {
-	counter       *counter
-	scanner       *bufio.Scanner
-	nodeGenerator *nodeGenerator
-}
-
-func newRootGeneratorSimple(r io.Reader) *rootGeneratorSimple {
-	return &rootGeneratorSimple{
+func newRootGeneratorSimple(ng nodeGenerator, newContents func() contentReader, renderer rowRenderer) rootGeneratorSimple {
+	return &defaultRootGeneratorSimple{
 		counter:       newCounter(),
-		scanner:       bufio.NewScanner(r),
-		nodeGenerator: newNodeGenerator(),
+		nodeGenerator: ng,
+		newContents:   newContents,
+		renderer:      renderer,
 	}
 }
 
-func (rg *rootGeneratorSimple) generate() ([]*Node, error) {
+type defaultRootGeneratorSimple struct {
+	counter       *counter
+	nodeGenerator nodeGenerator
+	newContents   func() contentReader
+	renderer      rowRenderer
+}
+
+func (rg *defaultRootGeneratorSimple) generate(r io.Reader) ([]*Node, error) {
 	var (
-		stack *stack
-		roots []*Node
+		scanner  = bufio.NewScanner(r)
+		stack    *stack
+		roots    []*Node
+		contents = rg.newContents()
 	)
 
-	for rg.scanner.Scan() {
-		currentNode, err := rg.nodeGenerator.generate(rg.scanner.Text(), rg.counter.next())
+	line := 0
+	for scanner.Scan() {
+		line++
+		row, err := rg.renderer.render(scanner.Text(), line)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := contents.read(row, line); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+
+		currentNode, err := rg.nodeGenerator.generate(row, line, rg.counter.next())
 		if err != nil {
 			return nil, err
 		}
@@ -44,6 +60,7 @@ func (rg *rootGeneratorSimple) generate() ([]*Node, error) {
 			roots = append(roots, currentNode)
 			stack = newStack()
 			stack.push(currentNode)
+			contents.setTarget(currentNode, row)
 			continue
 		}
 
@@ -51,28 +68,36 @@ func (rg *rootGeneratorSimple) generate() ([]*Node, error) {
 			return nil, errNilStack
 		}
 
-		stack.dfs(currentNode)
+		contents.setTarget(stack.dfs(currentNode), row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	return roots, rg.scanner.Err()
+	return roots, contents.close()
 }
 
-type rootGeneratorPipeline struct {
-	nodeGenerator *nodeGenerator
+func newRootGeneratorPipeline(ng nodeGenerator, newContents func() contentReader, renderer rowRenderer) rootGeneratorPipeline {
+	return &defaultRootGeneratorPipeline{
+		nodeGenerator: ng,
+		newContents:   newContents,
+		renderer:      renderer,
+	}
 }
 
-func newRootGeneratorPipeline() *rootGeneratorPipeline {
-	return &rootGeneratorPipeline{
-		nodeGenerator: newNodeGenerator(),
-	}
+type defaultRootGeneratorPipeline struct {
+	nodeGenerator nodeGenerator
+	newContents   func() contentReader
+	renderer      rowRenderer
 }
 
 const workerGenerateNum = 10
 
-func (rg *rootGeneratorPipeline) generate(ctx context.Context, blocks <-chan string) (<-chan *Node, <-chan error) {
+func (rg *defaultRootGeneratorPipeline) generate(ctx context.Context, r io.Reader) (<-chan *Node, <-chan error) {
 	rootc := make(chan *Node)
 	errc := make(chan error, 1)
 
+	blocks, errcsl := split(ctx, r, rg.nodeGenerator.isRootBlockBeginning)
 	go func() {
 		defer func() {
 			close(rootc)
@@ -85,12 +110,20 @@ func (rg *rootGeneratorPipeline) generate(ctx context.Context, blocks <-chan str
 			go rg.worker(ctx, wg, blocks, rootc, errc)
 		}
 		wg.Wait()
+
+		// 入力の読み込みエラー
+		if err, ok := <-errcsl; ok && err != nil {
+			select {
+			case errc <- err:
+			default:
+			}
+		}
 	}()
 
 	return rootc, errc
 }
 
-func (rg *rootGeneratorPipeline) worker(ctx context.Context, wg *sync.WaitGroup, blocks <-chan string, rootc chan<- *Node, errc chan<- error) {
+func (rg *defaultRootGeneratorPipeline) worker(ctx context.Context, wg *sync.WaitGroup, blocks <-chan *block, rootc chan<- *Node, errc chan<- error) {
 	defer wg.Done()
 	for {
 		select {
@@ -102,13 +135,27 @@ func (rg *rootGeneratorPipeline) worker(ctx context.Context, wg *sync.WaitGroup,
 			}
 
 			var (
-				sc      = bufio.NewScanner(strings.NewReader(block))
-				root    *Node
-				nodes   = newStack()
-				counter = newCounter()
+				sc       = bufio.NewScanner(strings.NewReader(block.content))
+				root     *Node
+				nodes    = newStack()
+				counter  = newCounter()
+				line     = block.startLine
+				contents = rg.newContents()
 			)
-			for sc.Scan() {
-				currentNode, err := rg.nodeGenerator.generate(sc.Text(), counter.next())
+			for ; sc.Scan(); line++ {
+				row, err := rg.renderer.render(sc.Text(), line)
+				if err != nil {
+					errc <- err
+					return
+				}
+				if ok, err := contents.read(row, line); err != nil {
+					errc <- err
+					return
+				} else if ok {
+					continue
+				}
+
+				currentNode, err := rg.nodeGenerator.generate(row, line, counter.next())
 				if err != nil {
 					errc <- err
 					return
@@ -120,6 +167,7 @@ func (rg *rootGeneratorPipeline) worker(ctx context.Context, wg *sync.WaitGroup,
 				if currentNode.isRoot() {
 					root = currentNode
 					nodes.push(currentNode)
+					contents.setTarget(currentNode, row)
 					continue
 				}
 
@@ -128,12 +176,20 @@ func (rg *rootGeneratorPipeline) worker(ctx context.Context, wg *sync.WaitGroup,
 					return
 				}
 
-				nodes.dfs(currentNode)
+				contents.setTarget(nodes.dfs(currentNode), row)
 			}
 			if err := sc.Err(); err != nil {
 				errc <- err
 				return
 			}
+			if err := contents.close(); err != nil {
+				errc <- err
+				return
+			}
+			if root == nil {
+				// ノードの無いブロック(例: tree を囲むフェンスの開始行のみ)
+				continue
+			}
 			select {
 			case <-ctx.Done():
 				return
@@ -142,3 +198,8 @@ func (rg *rootGeneratorPipeline) worker(ctx context.Context, wg *sync.WaitGroup,
 		}
 	}
 }
+
+var (
+	_ rootGeneratorSimple   = (*defaultRootGeneratorSimple)(nil)
+	_ rootGeneratorPipeline = (*defaultRootGeneratorPipeline)(nil)
+)
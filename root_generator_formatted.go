@@ -0,0 +1,255 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	toml "github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// jsonNode / yamlNode / tomlNode の逆変換先
+type formattedNode struct {
+	Name     string           `json:"value" yaml:"value" toml:"value"`
+	Comment  string           `json:"comment" yaml:"comment" toml:"comment"`
+	Meta     map[string]any   `json:"meta" yaml:"meta" toml:"meta"`
+	Children []*formattedNode `json:"children" yaml:"children" toml:"children"`
+}
+
+// 入力のドキュメント1つ分のRootを返し、入力の終わりで io.EOF を返す
+type formattedDecoder interface {
+	decode() ([]*formattedNode, error)
+}
+
+func newFormattedDecoder(decode decode, r io.Reader) formattedDecoder {
+	switch decode {
+	case decodeYAML:
+		return &yamlDecoder{decoder: yaml.NewDecoder(r)}
+	case decodeTOML:
+		return &tomlDecoder{r: r}
+	default:
+		return &jsonDecoder{decoder: json.NewDecoder(r)}
+	}
+}
+
+type jsonDecoder struct {
+	decoder *json.Decoder
+}
+
+func (jd *jsonDecoder) decode() ([]*formattedNode, error) {
+	raw := json.RawMessage{}
+	if err := jd.decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		fRoots := []*formattedNode{}
+		if err := json.Unmarshal(raw, &fRoots); err != nil {
+			return nil, err
+		}
+		return fRoots, nil
+	}
+
+	fRoot := &formattedNode{}
+	if err := json.Unmarshal(raw, fRoot); err != nil {
+		return nil, err
+	}
+	return []*formattedNode{fRoot}, nil
+}
+
+type yamlDecoder struct {
+	decoder *yaml.Decoder
+}
+
+func (yd *yamlDecoder) decode() ([]*formattedNode, error) {
+	doc := &yaml.Node{}
+	if err := yd.decoder.Decode(doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) != 0 && doc.Content[0].Kind == yaml.SequenceNode {
+		fRoots := []*formattedNode{}
+		if err := doc.Decode(&fRoots); err != nil {
+			return nil, err
+		}
+		return fRoots, nil
+	}
+
+	fRoot := &formattedNode{}
+	if err := doc.Decode(fRoot); err != nil {
+		return nil, err
+	}
+	return []*formattedNode{fRoot}, nil
+}
+
+// TOMLには複数ドキュメントの区切りが無いため、入力全体を1つのドキュメントとする。
+// 複数のRootは root キーのテーブルの配列 ([[root]]) で表す
+type tomlDecoder struct {
+	r    io.Reader
+	done bool
+}
+
+type tomlDocument struct {
+	formattedNode
+	Roots []*formattedNode `toml:"root"`
+}
+
+var errTOMLMixedRoots = errors.New("toml input must have either a root or [[root]] tables, not both")
+
+func (td *tomlDecoder) decode() ([]*formattedNode, error) {
+	if td.done {
+		return nil, io.EOF
+	}
+	td.done = true
+
+	b, err := io.ReadAll(td.r)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, io.EOF
+	}
+
+	doc := &tomlDocument{}
+	if err := toml.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Roots) == 0 {
+		return []*formattedNode{&doc.formattedNode}, nil
+	}
+	if len(doc.Name) != 0 || len(doc.Comment) != 0 || len(doc.Meta) != 0 || len(doc.Children) != 0 {
+		return nil, errTOMLMixedRoots
+	}
+	return doc.Roots, nil
+}
+
+// Markdownと同様に、同じ階層にある同名のノードはまとめる
+func toNode(fRoot *formattedNode) (*Node, error) {
+	if len(fRoot.Name) == 0 {
+		return nil, fmt.Errorf("%w: root", errEmptyText)
+	}
+
+	counter := newCounter()
+	root := newNode(fRoot.Name, rootHierarchyNum, counter.next())
+	root.comment = fRoot.Comment
+	root.mergeMeta(fRoot.Meta)
+	if err := addFormattedChildren(root, fRoot.Children, counter); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func addFormattedChildren(parent *Node, fChildren []*formattedNode, counter *counter) error {
+	for _, fChild := range fChildren {
+		if fChild == nil {
+			continue
+		}
+		if len(fChild.Name) == 0 {
+			return fmt.Errorf("%w: child of %s", errEmptyText, parent.name)
+		}
+
+		child := parent.findChildByText(fChild.Name)
+		if child == nil {
+			child = newNode(fChild.Name, parent.hierarchy+1, counter.next())
+			child.setParent(parent)
+			parent.addChild(child)
+		}
+		child.mergeComment(fChild.Comment)
+		child.mergeMeta(fChild.Meta)
+		if err := addFormattedChildren(child, fChild.Children, counter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newFormattedRootGeneratorSimple(decode decode) rootGeneratorSimple {
+	return &formattedRootGeneratorSimple{
+		decode: decode,
+	}
+}
+
+type formattedRootGeneratorSimple struct {
+	decode decode
+}
+
+func (fg *formattedRootGeneratorSimple) generate(r io.Reader) ([]*Node, error) {
+	decoder := newFormattedDecoder(fg.decode, r)
+	roots := []*Node{}
+	for {
+		fRoots, err := decoder.decode()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return roots, nil
+			}
+			return nil, err
+		}
+
+		for _, fRoot := range fRoots {
+			root, err := toNode(fRoot)
+			if err != nil {
+				return nil, err
+			}
+			roots = append(roots, root)
+		}
+	}
+}
+
+func newFormattedRootGeneratorPipeline(decode decode) rootGeneratorPipeline {
+	return &formattedRootGeneratorPipeline{
+		decode: decode,
+	}
+}
+
+type formattedRootGeneratorPipeline struct {
+	decode decode
+}
+
+func (fg *formattedRootGeneratorPipeline) generate(ctx context.Context, r io.Reader) (<-chan *Node, <-chan error) {
+	rootc := make(chan *Node)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			close(rootc)
+			close(errc)
+		}()
+
+		decoder := newFormattedDecoder(fg.decode, r)
+		for {
+			fRoots, err := decoder.decode()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					errc <- err
+				}
+				return
+			}
+
+			for _, fRoot := range fRoots {
+				root, err := toNode(fRoot)
+				if err != nil {
+					errc <- err
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case rootc <- root:
+				}
+			}
+		}
+	}()
+
+	return rootc, errc
+}
+
+var (
+	_ rootGeneratorSimple   = (*formattedRootGeneratorSimple)(nil)
+	_ rootGeneratorPipeline = (*formattedRootGeneratorPipeline)(nil)
+)
@@ -0,0 +1,80 @@
+package gtree
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// 注釈の列の前に最低限空ける幅
+const commentMargin = 2
+
+// Root配下をtreeの行として返す。注釈を持つ行は、最も長い行に揃えた列に注釈を付ける
+func spreadRows(root *Node) string {
+	nodes := []*Node{}
+	collectNodes(root, &nodes)
+
+	width := 0
+	if hasComment(root) {
+		width = maxRowWidth(nodes)
+	}
+
+	b := &strings.Builder{}
+	for _, n := range nodes {
+		r := n.row()
+		b.WriteString(n.highlightedRow())
+		if len(n.comment) != 0 {
+			b.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(r)+commentMargin))
+			b.WriteString("# " + n.comment)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func hasComment(current *Node) bool {
+	if len(current.comment) != 0 {
+		return true
+	}
+	for _, child := range current.children {
+		if hasComment(child) {
+			return true
+		}
+	}
+	return false
+}
+
+func collectNodes(current *Node, nodes *[]*Node) {
+	*nodes = append(*nodes, current)
+	for _, child := range current.children {
+		collectNodes(child, nodes)
+	}
+}
+
+func maxRowWidth(nodes []*Node) int {
+	width := 0
+	for _, n := range nodes {
+		width = max(width, utf8.RuneCountInString(n.row()))
+	}
+	return width
+}
+
+var highlightColor = color.New(color.Bold, color.FgHiYellow)
+
+func (n *Node) highlightedRow() string {
+	if !n.highlighted {
+		return n.row()
+	}
+	if n.isRoot() {
+		return highlightColor.Sprint(n.name)
+	}
+	return n.branch() + " " + highlightColor.Sprint(n.name)
+}
+
+func (n *Node) row() string {
+	if n.isRoot() {
+		return n.name
+	}
+	return n.branch() + " " + n.name
+}
@@ -9,41 +9,55 @@ import (
 )
 
 type treeSimple struct {
-	grower       growerSimple
-	spreader     spreaderSimple
-	mkdirer      mkdirerSimple
-	verifier     verifierSimple
-	growSpreader growSpreaderSimple
-	walker       walkerSimple
+	rootGenerator rootGeneratorSimple
+	grower        growerSimple
+	spreader      spreaderSimple
+	mkdirer       mkdirerSimple
+	verifier      verifierSimple
+	growSpreader  growSpreaderSimple
+	walker        walkerSimple
 }
 
 var _ tree = (*treeSimple)(nil)
 
 func newTreeSimple(cfg *config) tree {
-	growerFactory := func(lastNodeFormat, intermedialNodeFormat branchFormat, dryrun bool, encode encode) growerSimple {
-		if encode != encodeDefault {
-			return newNopGrowerSimple()
+	rootGeneratorFactory := func(decode decode, lastNodeFormat, intermedialNodeFormat branchFormat, renderer rowRenderer) rootGeneratorSimple {
+		switch decode {
+		case decodeJSON, decodeYAML, decodeTOML:
+			return newFormattedRootGeneratorSimple(decode)
+		default:
+			return newRootGeneratorSimple(
+				newNodeGenerator(decode, lastNodeFormat, intermedialNodeFormat),
+				newContentReaderFactory(decode),
+				renderer,
+			)
 		}
-		return newGrowerSimple(lastNodeFormat, intermedialNodeFormat, dryrun)
 	}
 
-	spreaderFactory := func(encode encode, dryrun bool, fileExtensions []string) spreaderSimple {
+	growerFactory := func(lastNodeFormat, intermedialNodeFormat branchFormat, dryrun bool, encode encode, sort nodeSorter, truncation truncation, pruning pruning, compaction compaction) growerSimple {
+		if !encode.formsBranches() {
+			return newNopGrowerSimple(sort, truncation, pruning, compaction)
+		}
+		return newGrowerSimple(lastNodeFormat, intermedialNodeFormat, dryrun, sort, truncation, pruning, compaction)
+	}
+
+	spreaderFactory := func(encode encode, dryrun bool, fileExtensions []string, fileContents fileContents, markdown markdownStyle, htmlStylesheet string, summarizer summarizer, template func(io.Writer, any) error) spreaderSimple {
 		if dryrun {
-			return newColorizeSpreaderSimple(fileExtensions)
+			return newColorizeSpreaderSimple(fileExtensions, fileContents)
 		}
-		return newSpreaderSimple(encode)
+		return newSpreaderSimple(encode, markdown, fileExtensions, htmlStylesheet, summarizer, template)
 	}
 
-	mkdirerFactory := func(targetDir string, fileExtensions []string) mkdirerSimple {
-		return newMkdirerSimple(targetDir, fileExtensions)
+	mkdirerFactory := func(targetDir string, fileExtensions []string, fileContents fileContents, policy mkdirPolicy) mkdirerSimple {
+		return newMkdirerSimple(targetDir, fileExtensions, fileContents, policy)
 	}
 
 	verifierFactory := func(targetDir string, strict bool) verifierSimple {
 		return newVerifierSimple(targetDir, strict)
 	}
 
-	growSpreaderFactory := func(lastNodeFormat, intermedialNodeFormat branchFormat) growSpreaderSimple {
-		return newGrowSpreaderSimple(lastNodeFormat, intermedialNodeFormat)
+	growSpreaderFactory := func(lastNodeFormat, intermedialNodeFormat branchFormat, sort nodeSorter, truncation truncation, pruning pruning, compaction compaction, summarizer summarizer) growSpreaderSimple {
+		return newGrowSpreaderSimple(lastNodeFormat, intermedialNodeFormat, sort, truncation, pruning, compaction, summarizer)
 	}
 
 	walkerFactory := func() walkerSimple {
@@ -51,20 +65,37 @@ func newTreeSimple(cfg *config) tree {
 	}
 
 	return &treeSimple{
+		rootGenerator: rootGeneratorFactory(
+			cfg.decode,
+			cfg.lastNodeFormat,
+			cfg.intermedialNodeFormat,
+			cfg.rowRenderer(),
+		),
 		grower: growerFactory(
 			cfg.lastNodeFormat,
 			cfg.intermedialNodeFormat,
 			cfg.dryrun,
 			cfg.encode,
+			cfg.nodeSorter(),
+			cfg.truncation(),
+			cfg.pruning(),
+			cfg.compaction(),
 		),
 		spreader: spreaderFactory(
 			cfg.encode,
 			cfg.dryrun,
 			cfg.fileExtensions,
+			cfg.fileContents(),
+			cfg.markdown,
+			cfg.htmlStylesheet,
+			cfg.summarizer(),
+			cfg.template,
 		),
 		mkdirer: mkdirerFactory(
 			cfg.targetDir,
 			cfg.fileExtensions,
+			cfg.fileContents(),
+			cfg.mkdirPolicy(),
 		),
 		verifier: verifierFactory(
 			cfg.targetDir,
@@ -73,13 +104,18 @@ func newTreeSimple(cfg *config) tree {
 		growSpreader: growSpreaderFactory(
 			cfg.lastNodeFormat,
 			cfg.intermedialNodeFormat,
+			cfg.nodeSorter(),
+			cfg.truncation(),
+			cfg.pruning(),
+			cfg.compaction(),
+			cfg.summarizer(),
 		),
 		walker: walkerFactory(),
 	}
 }
 
 func (t *treeSimple) output(w io.Writer, r io.Reader, cfg *config) error {
-	roots, err := newRootGeneratorSimple(r).generate()
+	roots, err := t.rootGenerator.generate(r)
 	if err != nil {
 		return err
 	}
@@ -103,7 +139,7 @@ func (t *treeSimple) outputProgrammably(w io.Writer, root *Node, cfg *config) er
 }
 
 func (t *treeSimple) mkdir(r io.Reader, cfg *config) error {
-	roots, err := newRootGeneratorSimple(r).generate()
+	roots, err := t.rootGenerator.generate(r)
 	if err != nil {
 		return err
 	}
@@ -129,7 +165,7 @@ func (t *treeSimple) mkdirProgrammably(root *Node, cfg *config) error {
 }
 
 func (t *treeSimple) verify(r io.Reader, cfg *config) error {
-	roots, err := newRootGeneratorSimple(r).generate()
+	roots, err := t.rootGenerator.generate(r)
 	if err != nil {
 		return err
 	}
@@ -151,7 +187,7 @@ func (t *treeSimple) verifyProgrammably(root *Node, cfg *config) error {
 }
 
 func (t *treeSimple) walk(r io.Reader, callback func(*WalkerNode) error, cfg *config) error {
-	roots, err := newRootGeneratorSimple(r).generate()
+	roots, err := t.rootGenerator.generate(r)
 	if err != nil {
 		return err
 	}
@@ -169,6 +205,11 @@ func (t *treeSimple) walkProgrammably(root *Node, callback func(*WalkerNode) err
 	return t.walker.walk([]*Node{root}, callback)
 }
 
+// 関心事は入力からのRootの生成
+type rootGeneratorSimple interface {
+	generate(io.Reader) ([]*Node, error)
+}
+
 // 関心事は各ノードの枝の形成
 type growerSimple interface {
 	grow([]*Node) error
@@ -9,19 +9,30 @@ import (
 
 func newGrowSpreaderSimple(
 	lastNodeFormat, intermedialNodeFormat branchFormat,
+	sort nodeSorter,
+	truncation truncation,
+	pruning pruning,
+	compaction compaction,
+	summarizer summarizer,
 ) growSpreaderSimple {
 	return &defaultGrowSpreaderSimple{
 		defaultGrowerSimple: &defaultGrowerSimple{
 			lastNodeFormat:        lastNodeFormat,
 			intermedialNodeFormat: intermedialNodeFormat,
 			enabledValidation:     false,
+			sort:                  sort,
+			truncation:            truncation,
+			pruning:               pruning,
+			compaction:            compaction,
 		},
+		summarizer: summarizer,
 	}
 }
 
 type defaultGrowSpreaderSimple struct {
 	*defaultGrowerSimple
-	w io.Writer
+	w          io.Writer
+	summarizer summarizer
 }
 
 func (dgs *defaultGrowSpreaderSimple) growAndSpread(w io.Writer, roots []*Node) error {
@@ -30,23 +41,49 @@ func (dgs *defaultGrowSpreaderSimple) growAndSpread(w io.Writer, roots []*Node)
 		if err := dgs.assembleAndPrint(root); err != nil {
 			return err
 		}
+		fmt.Fprint(dgs.w, dgs.summarizer.footer(root))
 	}
 	return nil
 }
 
-func (dgs *defaultGrowSpreaderSimple) assembleAndPrint(current *Node) error {
+func (dgs *defaultGrowSpreaderSimple) assembleAndPrint(root *Node) error {
+	dgs.pruning.prune(root)
+	dgs.compaction.compact(root)
+	if !hasComment(root) {
+		return dgs.assembleAndPrintBranch(root)
+	}
+
+	// 注釈の列を揃えるには全ての行の幅が必要なため、Root配下の枝を形成してから出力する
+	if err := dgs.assembleAll(root); err != nil {
+		return err
+	}
+	fmt.Fprint(dgs.w, spreadRows(root))
+	return nil
+}
+
+func (dgs *defaultGrowSpreaderSimple) assembleAndPrintBranch(current *Node) error {
 	if err := dgs.assembleBranch(current); err != nil {
 		return err
 	}
+	fmt.Fprintln(dgs.w, current.highlightedRow())
+
+	dgs.arrangeChildren(current)
+	for _, child := range current.children {
+		if err := dgs.assembleAndPrintBranch(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	ret := current.name + "\n"
-	if !current.isRoot() {
-		ret = current.branch() + " " + current.name + "\n"
+func (dgs *defaultGrowSpreaderSimple) assembleAll(current *Node) error {
+	if err := dgs.assembleBranch(current); err != nil {
+		return err
 	}
-	fmt.Fprint(dgs.w, ret)
 
+	dgs.arrangeChildren(current)
 	for _, child := range current.children {
-		if err := dgs.assembleAndPrint(child); err != nil {
+		if err := dgs.assembleAll(child); err != nil {
 			return err
 		}
 	}
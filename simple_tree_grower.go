@@ -5,11 +5,19 @@ package gtree
 func newGrowerSimple(
 	lastNodeFormat, intermedialNodeFormat branchFormat,
 	enabledValidation bool,
+	sort nodeSorter,
+	truncation truncation,
+	pruning pruning,
+	compaction compaction,
 ) growerSimple {
 	return &defaultGrowerSimple{
 		lastNodeFormat:        lastNodeFormat,
 		intermedialNodeFormat: intermedialNodeFormat,
 		enabledValidation:     enabledValidation,
+		sort:                  sort,
+		truncation:            truncation,
+		pruning:               pruning,
+		compaction:            compaction,
 	}
 }
 
@@ -17,6 +25,10 @@ type defaultGrowerSimple struct {
 	lastNodeFormat        branchFormat
 	intermedialNodeFormat branchFormat
 	enabledValidation     bool
+	sort                  nodeSorter
+	truncation            truncation
+	pruning               pruning
+	compaction            compaction
 }
 
 type branchFormat struct {
@@ -25,6 +37,8 @@ type branchFormat struct {
 
 func (dg *defaultGrowerSimple) grow(roots []*Node) error {
 	for _, root := range roots {
+		dg.pruning.prune(root)
+		dg.compaction.compact(root)
 		if err := dg.assemble(root); err != nil {
 			return err
 		}
@@ -37,6 +51,7 @@ func (dg *defaultGrowerSimple) assemble(current *Node) error {
 		return err
 	}
 
+	dg.arrangeChildren(current)
 	for _, child := range current.children {
 		if err := dg.assemble(child); err != nil {
 			return err
@@ -45,6 +60,12 @@ func (dg *defaultGrowerSimple) assemble(current *Node) error {
 	return nil
 }
 
+// 子の枝は兄弟の並び順と数で決まるため、子の枝を形成する前に並び替えて切り詰める
+func (dg *defaultGrowerSimple) arrangeChildren(current *Node) {
+	current.sortChildren(dg.sort)
+	dg.truncation.truncateChildren(current)
+}
+
 func (dg *defaultGrowerSimple) assembleBranch(current *Node) error {
 	current.clean() // 例えば、MkdirProgrammably funcでrootノードを使いまわすと、前回func実行時に形成されたノードの枝が残ったまま追記されてしまうため。
 
@@ -108,13 +129,39 @@ func (dg *defaultGrowerSimple) enableValidation() {
 	dg.enabledValidation = true
 }
 
-func newNopGrowerSimple() growerSimple {
-	return &nopGrowerSimple{}
+func newNopGrowerSimple(sort nodeSorter, truncation truncation, pruning pruning, compaction compaction) growerSimple {
+	return &nopGrowerSimple{
+		sort:       sort,
+		truncation: truncation,
+		pruning:    pruning,
+		compaction: compaction,
+	}
+}
+
+type nopGrowerSimple struct {
+	sort       nodeSorter
+	truncation truncation
+	pruning    pruning
+	compaction compaction
 }
 
-type nopGrowerSimple struct{}
+func (ng *nopGrowerSimple) grow(roots []*Node) error {
+	for _, root := range roots {
+		ng.pruning.prune(root)
+		ng.compaction.compact(root)
+		ng.arrange(root)
+	}
+	return nil
+}
 
-func (*nopGrowerSimple) grow(_ []*Node) error { return nil }
+// 枝を形成しない場合でも並び替えと切り詰めは行う
+func (ng *nopGrowerSimple) arrange(current *Node) {
+	current.sortChildren(ng.sort)
+	ng.truncation.truncateChildren(current)
+	for _, child := range current.children {
+		ng.arrange(child)
+	}
+}
 
 func (*nopGrowerSimple) enableValidation() {}
 
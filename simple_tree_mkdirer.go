@@ -4,9 +4,9 @@ package gtree
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 var (
@@ -14,7 +14,7 @@ var (
 	ErrExistPath = errors.New("path already exists")
 )
 
-func newMkdirerSimple(dir string, fileExtensions []string) mkdirerSimple {
+func newMkdirerSimple(dir string, fileExtensions []string, fileContents fileContents, policy mkdirPolicy) mkdirerSimple {
 	targetDir := "."
 	if len(dir) != 0 {
 		targetDir = dir
@@ -23,21 +23,32 @@ func newMkdirerSimple(dir string, fileExtensions []string) mkdirerSimple {
 	return &defaultMkdirerSimple{
 		targetDir:      targetDir,
 		fileConsiderer: newFileConsiderer(fileExtensions),
+		fileContents:   fileContents,
+		policy:         policy,
 	}
 }
 
 type defaultMkdirerSimple struct {
 	targetDir      string
 	fileConsiderer *fileConsiderer
+	fileContents   fileContents
+	policy         mkdirPolicy
 }
 
 func (dm *defaultMkdirerSimple) mkdir(roots []*Node) error {
-	if dm.isExistRoot(roots) {
+	record := newMkdirRecord(dm.policy.onCreate)
+	err := dm.makeRoots(roots, record)
+	dm.policy.fill(record)
+	return dm.rollbackRecord(record, err)
+}
+
+func (dm *defaultMkdirerSimple) makeRoots(roots []*Node, record *mkdirRecord) error {
+	if dm.policy.mode == MkdirFail && dm.failExistingRoots(roots, record) {
 		return ErrExistPath
 	}
 
 	for _, root := range roots {
-		if err := dm.makeDirectoriesAndFiles(root); err != nil {
+		if err := dm.makeRoot(root, record); err != nil {
 			return err
 		}
 	}
@@ -53,39 +64,147 @@ func (dm *defaultMkdirerSimple) isExistRoot(roots []*Node) bool {
 	return false
 }
 
-func (dm *defaultMkdirerSimple) makeDirectoriesAndFiles(current *Node) error {
-	if dm.fileConsiderer.isFile(current) {
-		dir := strings.TrimSuffix(current.path(), current.name)
-		if err := dm.mkdirAll(filepath.Join(dm.targetDir, dir)); err != nil {
-			return err
+// 既に存在するRootを失敗として記録する
+func (dm *defaultMkdirerSimple) failExistingRoots(roots []*Node, record *mkdirRecord) bool {
+	exists := false
+	for _, root := range roots {
+		if dm.isExistRoot([]*Node{root}) {
+			record.failed(root.path(), dm.fileConsiderer.isFile(root), ErrExistPath)
+			exists = true
+		}
+	}
+	return exists
+}
+
+func (dm *defaultMkdirerSimple) makeRoot(root *Node, record *mkdirRecord) error {
+	if dm.policy.skipsExistingRoot() && dm.isExistRoot([]*Node{root}) {
+		record.present(root.path(), dm.fileConsiderer.isFile(root))
+		return nil
+	}
+
+	if err := dm.mkdirTarget(record); err != nil {
+		record.failed(root.path(), dm.fileConsiderer.isFile(root), err)
+		return err
+	}
+	return dm.makeDirectoriesAndFiles(root, record)
+}
+
+// 作成先のディレクトリは報告の対象外だが、ロールバックのために作ったディレクトリを記録する
+func (dm *defaultMkdirerSimple) mkdirTarget(record *mkdirRecord) error {
+	missing := []string{}
+	for dir := filepath.Clean(dm.targetDir); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			break
 		}
-		return dm.mkfile(filepath.Join(dm.targetDir, current.path()))
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if len(missing) == 0 {
+		return nil
 	}
 
-	if !current.hasChild() {
-		return dm.mkdirAll(filepath.Join(dm.targetDir, current.path()))
+	if err := os.MkdirAll(dm.targetDir, permission); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		record.madeTargetDir(missing[i])
+	}
+	return nil
+}
+
+// 親から順に1つずつ作り、各パスの結果を記録する
+func (dm *defaultMkdirerSimple) makeDirectoriesAndFiles(current *Node, record *mkdirRecord) error {
+	isFile := dm.fileConsiderer.isFile(current)
+	if err := dm.makeNode(current, isFile, record); err != nil {
+		record.failed(current.path(), isFile, err)
+		return err
+	}
+	if isFile {
+		return nil
 	}
 
 	for _, child := range current.children {
-		if err := dm.makeDirectoriesAndFiles(child); err != nil {
+		if err := dm.makeDirectoriesAndFiles(child, record); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func (dm *defaultMkdirerSimple) makeNode(current *Node, isFile bool, record *mkdirRecord) error {
+	if isFile {
+		content, err := dm.fileContents.content(current)
+		if err != nil {
+			return err
+		}
+		return dm.mkfile(current.path(), content, record)
+	}
+	if current.content != nil && current.hasChild() {
+		return fmt.Errorf("%w: %s", errContentOfDirectory, current.path())
+	}
+	return dm.mkdirOne(current.path(), record)
+}
+
 const permission = 0o755
 
-func (*defaultMkdirerSimple) mkdirAll(dir string) error {
-	return os.MkdirAll(dir, permission)
+func (dm *defaultMkdirerSimple) mkdirOne(path string, record *mkdirRecord) error {
+	dir := filepath.Join(dm.targetDir, path)
+	fi, err := os.Stat(dir)
+	switch {
+	case err == nil && fi.IsDir():
+		record.present(path, false)
+		return nil
+	case err == nil:
+		return fmt.Errorf("%w: %s", errExistAsFile, path)
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if err := os.Mkdir(dir, permission); err != nil {
+		// Massiveモードで同名のRootを別のワーカーが先に作った場合
+		if fi, serr := os.Stat(dir); os.IsExist(err) && serr == nil && fi.IsDir() {
+			record.present(path, false)
+			return nil
+		}
+		return err
+	}
+	record.createdDir(path)
+	return nil
 }
 
-func (*defaultMkdirerSimple) mkfile(path string) error {
-	f, err := os.Create(path)
-	if err != nil {
+func (dm *defaultMkdirerSimple) mkfile(path string, content []byte, record *mkdirRecord) error {
+	file := filepath.Join(dm.targetDir, path)
+	fi, err := os.Stat(file)
+	switch {
+	case err == nil && fi.IsDir():
+		return fmt.Errorf("%w: %s", errExistAsDirectory, path)
+	case err == nil && !dm.policy.overwritesFile():
+		record.present(path, true)
+		return nil
+	case err == nil:
+		if dm.policy.rollback {
+			b, err := backupFile(file, path)
+			if err != nil {
+				return err
+			}
+			record.backup(b)
+		}
+		if err := writeFileAtomically(file, content, fi.Mode().Perm()); err != nil {
+			return err
+		}
+		record.overwritten(path)
+		return nil
+	case !os.IsNotExist(err):
 		return err
 	}
-	return f.Close()
+
+	if err := createFile(file, content); err != nil {
+		return err
+	}
+	record.createdFile(path)
+	return nil
 }
 
 var _ mkdirerSimple = (*defaultMkdirerSimple)(nil)
@@ -6,14 +6,19 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"slices"
+	"strconv"
+	"strings"
 
+	md "github.com/ddddddO/gtree/markdown"
 	"github.com/fatih/color"
 	toml "github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
-func newSpreaderSimple(encode encode) spreaderSimple {
+func newSpreaderSimple(encode encode, markdown markdownStyle, fileExtensions []string, htmlStylesheet string, summarizer summarizer, template func(io.Writer, any) error) spreaderSimple {
 	switch encode {
 	case encodeJSON:
 		return newJSONSpreaderSimple()
@@ -21,8 +26,18 @@ func newSpreaderSimple(encode encode) spreaderSimple {
 		return newYAMLSpreaderSimple()
 	case encodeTOML:
 		return newTOMLSpreaderSimple()
+	case encodeMarkdown:
+		return newMarkdownSpreaderSimple(markdown)
+	case encodeMermaid:
+		return newGraphSpreaderSimple(&mermaidFormat{})
+	case encodeDOT:
+		return newGraphSpreaderSimple(&dotFormat{})
+	case encodeHTML:
+		return newHTMLSpreaderSimple(fileExtensions, htmlStylesheet)
+	case encodeTemplate:
+		return newTemplateSpreaderSimple(template, fileExtensions)
 	default:
-		return &defaultSpreaderSimple{}
+		return &defaultSpreaderSimple{summarizer: summarizer}
 	}
 }
 
@@ -33,41 +48,46 @@ const (
 	encodeJSON
 	encodeYAML
 	encodeTOML
+	encodeMarkdown
+	encodeMermaid
+	encodeDOT
+	encodeHTML
+	encodeTemplate
 )
 
+// 枝を出力に使う形式か
+func (e encode) formsBranches() bool {
+	return e == encodeDefault || e == encodeTemplate
+}
+
 type defaultSpreaderSimple struct {
-	w io.Writer
+	w          io.Writer
+	summarizer summarizer
 }
 
 func (ds *defaultSpreaderSimple) spread(w io.Writer, roots []*Node) error {
 	ds.w = w
 	for _, root := range roots {
-		ds.spreadBranch(root)
+		ds.spreadRoot(root)
 	}
 	return nil
 }
 
-func (ds *defaultSpreaderSimple) spreadBranch(current *Node) {
-	ret := current.name + "\n"
-	if !current.isRoot() {
-		ret = current.branch() + " " + current.name + "\n"
-	}
-	fmt.Fprint(ds.w, ret)
-
-	for _, child := range current.children {
-		ds.spreadBranch(child)
-	}
+func (ds *defaultSpreaderSimple) spreadRoot(root *Node) {
+	fmt.Fprint(ds.w, spreadRows(root)+ds.summarizer.footer(root))
 }
 
 type formattedSpreaderSimple[T sitter] struct {
-	formattedRoot func(string) T
+	formattedRoot func(*Node) T
 	encode        func(io.Writer) func(any) error
+	// 複数のRootを1つの値にまとめて出力する形式で、Rootをまとめる値を返す
+	rootsValue func(T) any
 }
 
 func newJSONSpreaderSimple() *formattedSpreaderSimple[*jsonNode] {
 	return &formattedSpreaderSimple[*jsonNode]{
-		formattedRoot: func(name string) *jsonNode {
-			return &jsonNode{Name: name}
+		formattedRoot: func(root *Node) *jsonNode {
+			return &jsonNode{Name: root.name, Comment: root.comment, Meta: root.meta, Truncated: root.truncated}
 		},
 		encode: func(w io.Writer) func(any) error {
 			return json.NewEncoder(w).Encode
@@ -77,8 +97,8 @@ func newJSONSpreaderSimple() *formattedSpreaderSimple[*jsonNode] {
 
 func newYAMLSpreaderSimple() *formattedSpreaderSimple[*yamlNode] {
 	return &formattedSpreaderSimple[*yamlNode]{
-		formattedRoot: func(name string) *yamlNode {
-			return &yamlNode{Name: name}
+		formattedRoot: func(root *Node) *yamlNode {
+			return &yamlNode{Name: root.name, Comment: root.comment, Meta: root.meta, Truncated: root.truncated}
 		},
 		encode: func(w io.Writer) func(any) error {
 			return yaml.NewEncoder(w).Encode
@@ -88,33 +108,45 @@ func newYAMLSpreaderSimple() *formattedSpreaderSimple[*yamlNode] {
 
 func newTOMLSpreaderSimple() *formattedSpreaderSimple[*tomlNode] {
 	return &formattedSpreaderSimple[*tomlNode]{
-		formattedRoot: func(name string) *tomlNode {
-			return &tomlNode{Name: name}
+		formattedRoot: func(root *Node) *tomlNode {
+			return &tomlNode{Name: root.name, Comment: root.comment, Meta: root.meta, Truncated: root.truncated}
 		},
 		encode: func(w io.Writer) func(any) error {
 			return toml.NewEncoder(w).Encode
 		},
+		rootsValue: tomlRootsValue,
 	}
 }
 
 func (f *formattedSpreaderSimple[T]) spread(w io.Writer, roots []*Node) error {
 	encode := f.encode(w)
 	for _, root := range roots {
-		fRoot := toFormattedNode(root, f.formattedRoot(root.name))
-		if err := encode(fRoot); err != nil {
+		if err := encode(formattedValue(root, f.formattedRoot, f.rootsValue, len(roots) > 1)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// multiRoot の場合は、rootsValue があればそれでまとめる
+func formattedValue[T sitter](root *Node, formattedRoot func(*Node) T, rootsValue func(T) any, multiRoot bool) any {
+	fRoot := toFormattedNode(root, formattedRoot(root))
+	if multiRoot && rootsValue != nil {
+		return rootsValue(fRoot)
+	}
+	return fRoot
+}
+
 type jsonNode struct {
-	Name     string      `json:"value"`
-	Children []*jsonNode `json:"children"`
+	Name      string         `json:"value"`
+	Comment   string         `json:"comment,omitempty"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	Truncated int            `json:"truncated,omitempty"`
+	Children  []*jsonNode    `json:"children"`
 }
 
-func (jn *jsonNode) setChild(name string) {
-	jn.Children = append(jn.Children, &jsonNode{Name: name})
+func (jn *jsonNode) setChild(child *Node) {
+	jn.Children = append(jn.Children, &jsonNode{Name: child.name, Comment: child.comment, Meta: child.meta, Truncated: child.truncated})
 }
 
 func (jn *jsonNode) getChild(i int) sitter {
@@ -122,12 +154,25 @@ func (jn *jsonNode) getChild(i int) sitter {
 }
 
 type tomlNode struct {
-	Name     string      `toml:"value"`
-	Children []*tomlNode `toml:"children"`
+	Name      string         `toml:"value"`
+	Comment   string         `toml:"comment,omitempty"`
+	Meta      map[string]any `toml:"meta,omitempty"`
+	Truncated int            `toml:"truncated,omitempty"`
+	Children  []*tomlNode    `toml:"children"`
 }
 
-func (tn *tomlNode) setChild(name string) {
-	tn.Children = append(tn.Children, &tomlNode{Name: name})
+// TOMLには複数ドキュメントの区切りが無いため、複数のRootはテーブルの配列 ([[root]]) とする。
+// 1つずつ出力しても、続けて読むと1つの配列になる
+type tomlRoots struct {
+	Roots []*tomlNode `toml:"root"`
+}
+
+func tomlRootsValue(fRoot *tomlNode) any {
+	return &tomlRoots{Roots: []*tomlNode{fRoot}}
+}
+
+func (tn *tomlNode) setChild(child *Node) {
+	tn.Children = append(tn.Children, &tomlNode{Name: child.name, Comment: child.comment, Meta: child.meta, Truncated: child.truncated})
 }
 
 func (tn *tomlNode) getChild(i int) sitter {
@@ -135,12 +180,15 @@ func (tn *tomlNode) getChild(i int) sitter {
 }
 
 type yamlNode struct {
-	Name     string      `yaml:"value"`
-	Children []*yamlNode `yaml:"children"`
+	Name      string         `yaml:"value"`
+	Comment   string         `yaml:"comment,omitempty"`
+	Meta      map[string]any `yaml:"meta,omitempty"`
+	Truncated int            `yaml:"truncated,omitempty"`
+	Children  []*yamlNode    `yaml:"children"`
 }
 
-func (yn *yamlNode) setChild(name string) {
-	yn.Children = append(yn.Children, &yamlNode{Name: name})
+func (yn *yamlNode) setChild(child *Node) {
+	yn.Children = append(yn.Children, &yamlNode{Name: child.name, Comment: child.comment, Meta: child.meta, Truncated: child.truncated})
 }
 
 func (yn *yamlNode) getChild(i int) sitter {
@@ -148,7 +196,7 @@ func (yn *yamlNode) getChild(i int) sitter {
 }
 
 type sitter interface {
-	setChild(string)
+	setChild(*Node)
 	getChild(int) sitter
 }
 
@@ -157,19 +205,330 @@ func toFormattedNode[T sitter](parent *Node, fParent T) T {
 		return fParent
 	}
 
-	for i := range parent.children {
-		fParent.setChild(parent.children[i].name)
-		toFormattedNode(parent.children[i], fParent.getChild(i).(T))
+	i := 0
+	for _, child := range parent.children {
+		// 隠したノードは集計ノードではなく truncated の数として表す
+		if child.isSummary {
+			continue
+		}
+		fParent.setChild(child)
+		toFormattedNode(child, fParent.getChild(i).(T))
+		i++
 	}
 
 	return fParent
 }
 
-func newColorizeSpreaderSimple(fileExtensions []string) spreaderSimple {
+func newMarkdownSpreaderSimple(style markdownStyle) *markdownSpreaderSimple {
+	indent := "\t"
+	if style.indent > 0 {
+		indent = strings.Repeat(" ", style.indent)
+	}
+	return &markdownSpreaderSimple{
+		bullet: style.bullet + " ",
+		indent: indent,
+	}
+}
+
+// gtree で入力可能なMarkdownとして出力する
+type markdownSpreaderSimple struct {
+	bullet string
+	indent string
+}
+
+func (ms *markdownSpreaderSimple) spread(w io.Writer, roots []*Node) error {
+	buf := bufio.NewWriter(w)
+	for _, root := range roots {
+		if err := ms.spreadRoot(buf, root); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+func (ms *markdownSpreaderSimple) spreadRoot(w io.Writer, root *Node) error {
+	if _, err := io.WriteString(w, ms.spreadBranch(root)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ms *markdownSpreaderSimple) spreadBranch(current *Node) string {
+	ret := strings.Repeat(ms.indent, int(current.hierarchy-rootHierarchyNum)) + ms.bullet + md.Escape(current.name)
+	if len(current.meta) != 0 {
+		ret += " " + formatMeta(current.meta)
+	}
+	if len(current.comment) != 0 {
+		ret += " # " + current.comment
+	}
+	ret += "\n"
+	if current.content != nil {
+		ret += formatContent(current.content, current.contentInfo, strings.Repeat(ms.indent, int(current.hierarchy)))
+	}
+	for _, child := range current.children {
+		// gtree の入力として読めるように、集計ノードは出力しない
+		if child.isSummary {
+			continue
+		}
+		ret += ms.spreadBranch(child)
+	}
+	return ret
+}
+
+// ノードの下にインデントしたコードブロックにする。内容にフェンスと同じ文字の並びで始まる行があれば、それより長いフェンスで囲む
+func formatContent(content []byte, info, indent string) string {
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(content) == 0 {
+		lines = nil
+	}
+
+	// "`" のフェンスの情報文字列には "`" を含められない
+	c := "`"
+	if strings.Contains(info, "`") {
+		c = "~"
+	}
+	fence := strings.Repeat(c, 3)
+	for _, l := range lines {
+		trimmed := strings.TrimLeft(l, indentChars)
+		if run := len(trimmed) - len(strings.TrimLeft(trimmed, c)); run >= len(fence) {
+			fence = strings.Repeat(c, run+1)
+		}
+	}
+
+	ret := indent + fence + info + "\n"
+	for _, l := range lines {
+		if len(l) != 0 {
+			ret += indent + l
+		}
+		ret += "\n"
+	}
+	return ret + indent + fence + "\n"
+}
+
+// Markdownの "{key: value}" の形式にする。キーは昇順
+func formatMeta(meta map[string]any) string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	entries := make([]string, len(keys))
+	for i, k := range keys {
+		v := fmt.Sprint(meta[k])
+		// 注釈の区切りを含む値も、注釈とされないように引用符で囲む
+		if len(v) == 0 || strings.ContainsAny(v, `,{}"#`) || strings.Contains(v, "--") || strings.TrimSpace(v) != v {
+			v = strconv.Quote(v)
+		}
+		entries[i] = k + ": " + v
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// 出力全体で1つの文書となり、Rootの前後に文書の先頭と末尾を出力する形式
+type documentSpreaderSimple interface {
+	header() string
+	spreadRoot(w io.Writer, root *Node, rootIdx int) error
+	footer() string
+}
+
+func spreadDocument(w io.Writer, ds documentSpreaderSimple, roots []*Node) error {
+	buf := bufio.NewWriter(w)
+	if _, err := buf.WriteString(ds.header()); err != nil {
+		return err
+	}
+	for i, root := range roots {
+		if err := ds.spreadRoot(buf, root, i); err != nil {
+			return err
+		}
+	}
+	if _, err := buf.WriteString(ds.footer()); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// Mermaid / DOT のように、ノードの宣言と親子の辺で木を表す形式
+type graphFormat interface {
+	header() string
+	root(id, name string) string
+	child(parentID, id, name string) string
+	// WithMaxDepth / WithMaxChildren で隠したノードの集計ノードを、通常のノードと見分けられるように表す
+	summary(parentID, id, name string) string
+	footer() string
+}
+
+func newGraphSpreaderSimple(format graphFormat) *graphSpreaderSimple {
+	return &graphSpreaderSimple{
+		graphFormat: format,
+	}
+}
+
+type graphSpreaderSimple struct {
+	graphFormat
+}
+
+func (gs *graphSpreaderSimple) spread(w io.Writer, roots []*Node) error {
+	return spreadDocument(w, gs, roots)
+}
+
+// 同じ名前のノードが複数あっても区別できるように、ノードのIDはRootの順番と兄弟内の位置から決める。e.g. n0_2_1
+func (gs *graphSpreaderSimple) spreadRoot(w io.Writer, root *Node, rootIdx int) error {
+	id := fmt.Sprintf("n%d", rootIdx)
+	if _, err := io.WriteString(w, gs.root(id, root.name)); err != nil {
+		return err
+	}
+	return gs.spreadChildren(w, root, id)
+}
+
+func (gs *graphSpreaderSimple) spreadChildren(w io.Writer, parent *Node, parentID string) error {
+	for i, child := range parent.children {
+		id := fmt.Sprintf("%s_%d", parentID, i)
+		if child.isSummary {
+			if _, err := io.WriteString(w, gs.summary(parentID, id, child.name)); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := io.WriteString(w, gs.child(parentID, id, child.name)); err != nil {
+			return err
+		}
+		if err := gs.spreadChildren(w, child, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mermaid の flowchart (graph TD)
+type mermaidFormat struct{}
+
+func (*mermaidFormat) header() string { return "graph TD\n" }
+
+func (*mermaidFormat) root(id, name string) string {
+	return fmt.Sprintf("    %s[\"%s\"]\n", id, escapeMermaid(name))
+}
+
+func (*mermaidFormat) child(parentID, id, name string) string {
+	return fmt.Sprintf("    %s --> %s[\"%s\"]\n", parentID, id, escapeMermaid(name))
+}
+
+// 点線の辺と角の丸いノード
+func (*mermaidFormat) summary(parentID, id, name string) string {
+	return fmt.Sprintf("    %s -.-> %s([\"%s\"])\n", parentID, id, escapeMermaid(name))
+}
+
+func (*mermaidFormat) footer() string { return "" }
+
+// エンティティコード自体が # で始まるため、# を最初に置き換える
+var mermaidReplacer = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\n", " ",
+)
+
+func escapeMermaid(name string) string {
+	return mermaidReplacer.Replace(name)
+}
+
+// Graphviz の DOT 言語
+type dotFormat struct{}
+
+func (*dotFormat) header() string { return "digraph gtree {\n" }
+
+func (*dotFormat) root(id, name string) string {
+	return fmt.Sprintf("    %s [label=\"%s\"];\n", id, escapeDOT(name))
+}
+
+func (*dotFormat) child(parentID, id, name string) string {
+	return fmt.Sprintf("    %s [label=\"%s\"];\n    %s -> %s;\n", id, escapeDOT(name), parentID, id)
+}
+
+// 破線のノードと辺
+func (*dotFormat) summary(parentID, id, name string) string {
+	return fmt.Sprintf("    %s [label=\"%s\", style=dashed];\n    %s -> %s [style=dashed];\n", id, escapeDOT(name), parentID, id)
+}
+
+func (*dotFormat) footer() string { return "}\n" }
+
+var dotReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+func escapeDOT(name string) string {
+	return dotReplacer.Replace(name)
+}
+
+func newHTMLSpreaderSimple(fileExtensions []string, stylesheet string) *htmlSpreaderSimple {
+	return &htmlSpreaderSimple{
+		fileConsiderer: newFileConsiderer(fileExtensions),
+		stylesheet:     stylesheet,
+	}
+}
+
+// Rootごとに入れ子の <details> / <summary> で表した、1つのHTMLページとして出力する
+type htmlSpreaderSimple struct {
+	fileConsiderer *fileConsiderer
+	stylesheet     string
+}
+
+func (hs *htmlSpreaderSimple) spread(w io.Writer, roots []*Node) error {
+	return spreadDocument(w, hs, roots)
+}
+
+func (hs *htmlSpreaderSimple) header() string {
+	head := "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>gtree</title>\n"
+	if len(hs.stylesheet) != 0 {
+		head += "<style>\n" + hs.stylesheet + "\n</style>\n"
+	}
+	return head + "</head>\n<body>\n<div class=\"gtree\">\n"
+}
+
+func (*htmlSpreaderSimple) footer() string {
+	return "</div>\n</body>\n</html>\n"
+}
+
+func (hs *htmlSpreaderSimple) spreadRoot(w io.Writer, root *Node, _ int) error {
+	_, err := io.WriteString(w, hs.spreadBranch(root))
+	return err
+}
+
+func (hs *htmlSpreaderSimple) spreadBranch(current *Node) string {
+	indent := strings.Repeat("  ", int(current.hierarchy-rootHierarchyNum))
+	name := html.EscapeString(current.name)
+	if current.isSummary {
+		return fmt.Sprintf("%s<div class=\"summary\">%s</div>\n", indent, name)
+	}
+	if !current.hasChild() {
+		class := "dir"
+		if hs.fileConsiderer.isFile(current) {
+			class = "file"
+		}
+		return fmt.Sprintf("%s<div class=\"%s\">%s</div>\n", indent, class, name)
+	}
+
+	// 大きなツリーでも見通しが良いように、Rootのみ開いておく
+	open := ""
+	if current.isRoot() {
+		open = " open"
+	}
+	ret := fmt.Sprintf("%s<details class=\"dir\"%s><summary>%s</summary>\n", indent, open, name)
+	for _, child := range current.children {
+		ret += hs.spreadBranch(child)
+	}
+	return ret + indent + "</details>\n"
+}
+
+func newColorizeSpreaderSimple(fileExtensions []string, fileContents fileContents) spreaderSimple {
 	return &colorizeSpreaderSimple{
 		defaultSpreaderSimple: &defaultSpreaderSimple{},
 
 		fileConsiderer: newFileConsiderer(fileExtensions),
+		fileContents:   fileContents,
 		fileColor:      color.New(color.Bold, color.FgHiCyan),
 		fileCounter:    newCounter(),
 
@@ -182,6 +541,7 @@ type colorizeSpreaderSimple struct {
 	*defaultSpreaderSimple
 
 	fileConsiderer *fileConsiderer
+	fileContents   fileContents
 	fileColor      *color.Color
 	fileCounter    *counter
 
@@ -194,23 +554,33 @@ func (cs *colorizeSpreaderSimple) spread(w io.Writer, roots []*Node) error {
 	for _, root := range roots {
 		cs.fileCounter.reset()
 		cs.dirCounter.reset()
-		ret += fmt.Sprintf("%s\n%s\n", cs.spreadBranch(root), cs.summary())
+		branch, err := cs.spreadBranch(root)
+		if err != nil {
+			return err
+		}
+		ret += fmt.Sprintf("%s\n%s\n", branch, cs.summary())
 	}
 	return cs.write(w, ret)
 }
 
-func (cs *colorizeSpreaderSimple) spreadBranch(current *Node) string {
-	ret := ""
-	if current.isRoot() {
-		ret = cs.colorize(current) + "\n"
-	} else {
-		ret = current.branch() + " " + cs.colorize(current) + "\n"
+func (cs *colorizeSpreaderSimple) spreadBranch(current *Node) (string, error) {
+	name, err := cs.colorize(current)
+	if err != nil {
+		return "", err
+	}
+	ret := name + "\n"
+	if !current.isRoot() {
+		ret = current.branch() + " " + ret
 	}
 
 	for _, child := range current.children {
-		ret += cs.spreadBranch(child)
+		branch, err := cs.spreadBranch(child)
+		if err != nil {
+			return "", err
+		}
+		ret += branch
 	}
-	return ret
+	return ret, nil
 }
 
 func (*colorizeSpreaderSimple) write(w io.Writer, in string) error {
@@ -221,13 +591,21 @@ func (*colorizeSpreaderSimple) write(w io.Writer, in string) error {
 	return buf.Flush()
 }
 
-func (cs *colorizeSpreaderSimple) colorize(current *Node) string {
+func (cs *colorizeSpreaderSimple) colorize(current *Node) (string, error) {
 	if cs.fileConsiderer.isFile(current) {
 		_ = cs.fileCounter.next()
-		return cs.fileColor.Sprint(current.name)
+		content, err := cs.fileContents.content(current)
+		if err != nil {
+			return "", err
+		}
+		// 内容を書き込むファイルには、そのバイト数を添える
+		if content != nil {
+			return fmt.Sprintf("%s (%s)", cs.fileColor.Sprint(current.name), plural(len(content), "byte", "bytes")), nil
+		}
+		return cs.fileColor.Sprint(current.name), nil
 	} else {
 		_ = cs.dirCounter.next()
-		return cs.dirColor.Sprint(current.name)
+		return cs.dirColor.Sprint(current.name), nil
 	}
 }
 
@@ -242,5 +620,11 @@ func (cs *colorizeSpreaderSimple) summary() string {
 var (
 	_ spreaderSimple = (*defaultSpreaderSimple)(nil)
 	_ spreaderSimple = (*formattedSpreaderSimple[sitter])(nil)
-	_ spreaderSimple = (*colorizeSpreaderSimple)(nil)
+	_ spreaderSimple = (*markdownSpreaderSimple)(nil)
+	_ spreaderSimple = (*graphSpreaderSimple)(nil)
+	_ spreaderSimple = (*htmlSpreaderSimple)(nil)
+
+	_ documentSpreaderSimple = (*graphSpreaderSimple)(nil)
+	_ documentSpreaderSimple = (*htmlSpreaderSimple)(nil)
+	_ spreaderSimple         = (*colorizeSpreaderSimple)(nil)
 )
@@ -5,6 +5,9 @@ package gtree
 // WalkerNode is used in user-defined function that can be executed with Walk/WalkProgrammably function.
 type WalkerNode struct {
 	origin *Node
+
+	// WithSort の比較時のみ設定される
+	fileConsiderer *fileConsiderer
 }
 
 // Name returns name of node in completed tree structure.
@@ -37,11 +40,23 @@ func (wn *WalkerNode) Path() string {
 	return wn.origin.path()
 }
 
+// Meta returns the metadata of the node set with the key. It returns nil if it has not been set.
+func (wn *WalkerNode) Meta(key string) any {
+	return wn.origin.meta[key]
+}
+
 // HasChild returns whether the node in completed tree structure has child nodes.
 func (wn *WalkerNode) HasChild() bool {
 	return wn.origin.hasChild()
 }
 
+func (wn *WalkerNode) isFile() bool {
+	if wn.fileConsiderer == nil {
+		return false
+	}
+	return wn.fileConsiderer.isFile(wn.origin)
+}
+
 func newWalkerSimple() walkerSimple {
 	return &defaultWalkerSimple{}
 }
@@ -0,0 +1,133 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithSort returns function for sorting sibling nodes instead of keeping the input order.
+// Each comparator returns a negative number when a should come before b, a positive number when b should come before a, and zero when they are equal.
+// When multiple comparators are specified, the next comparator is used only if the previous one returns zero. Nodes that are equal in all comparators keep the input order.
+// Since the nodes are sorted before their branches are formed, only Name, Level and HasChild of WalkerNode are available in comparators.
+func WithSort(cmps ...func(a, b *WalkerNode) int) Option {
+	return func(c *config) {
+		cmps := slices.DeleteFunc(slices.Clone(cmps), func(cmp func(a, b *WalkerNode) int) bool { return cmp == nil })
+		if len(cmps) == 0 {
+			c.sort = nil
+			return
+		}
+		c.sort = func(fc *fileConsiderer) nodeSorter {
+			return func(siblings []*Node) {
+				walkers := make([]*WalkerNode, len(siblings))
+				for i, s := range siblings {
+					walkers[i] = &WalkerNode{origin: s, fileConsiderer: fc}
+				}
+				slices.SortStableFunc(walkers, func(a, b *WalkerNode) int {
+					for _, cmp := range cmps {
+						if ret := cmp(a, b); ret != 0 {
+							return ret
+						}
+					}
+					return 0
+				})
+				for i, w := range walkers {
+					siblings[i] = w.origin
+				}
+			}
+		}
+	}
+}
+
+// WithFileExtensions が WithSort より後に指定される場合もあるため、全てのオプションを適用した後に組み立てる
+func (c *config) nodeSorter() nodeSorter {
+	if c.sort == nil {
+		return nil
+	}
+	return c.sort(newFileConsiderer(c.fileExtensions))
+}
+
+// SortByName is a comparator for WithSort that sorts nodes by name in ascending order.
+func SortByName(a, b *WalkerNode) int {
+	return strings.Compare(a.Name(), b.Name())
+}
+
+// SortByNameDesc is a comparator for WithSort that sorts nodes by name in descending order.
+func SortByNameDesc(a, b *WalkerNode) int {
+	return strings.Compare(b.Name(), a.Name())
+}
+
+// SortNatural is a comparator for WithSort that sorts nodes by name in natural order.
+// Sequences of digits in names are compared as numbers. For example, "v1.9" comes before "v1.10" and "file2" comes before "file10".
+func SortNatural(a, b *WalkerNode) int {
+	return compareNatural(a.Name(), b.Name())
+}
+
+// SortDirsFirst is a comparator for WithSort that places directories before files.
+// Whether a node is a file is determined in the same way as Mkdir function, that is, by the extensions specified with WithFileExtensions.
+// Combine it with another comparator to sort the directories and the files respectively, e.g. WithSort(SortDirsFirst, SortByName).
+func SortDirsFirst(a, b *WalkerNode) int {
+	switch aFile, bFile := a.isFile(), b.isFile(); {
+	case aFile == bFile:
+		return 0
+	case bFile:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (n *Node) sortChildren(sort nodeSorter) {
+	if sort == nil || len(n.children) < 2 {
+		return
+	}
+	sort(n.children)
+}
+
+// 数字の並びは数値として比較し、数値が等しければ桁数の少ない方("01" より "1")を先にする
+func compareNatural(a, b string) int {
+	for len(a) > 0 && len(b) > 0 {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			aNum, aRest := cutDigits(a)
+			bNum, bRest := cutDigits(b)
+			if ret := compareNumeric(aNum, bNum); ret != 0 {
+				return ret
+			}
+			a, b = aRest, bRest
+			continue
+		}
+
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+func compareNumeric(a, b string) int {
+	trimmedA, trimmedB := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(trimmedA) != len(trimmedB) {
+		return len(trimmedA) - len(trimmedB)
+	}
+	if ret := strings.Compare(trimmedA, trimmedB); ret != 0 {
+		return ret
+	}
+	return len(a) - len(b)
+}
+
+func cutDigits(s string) (digits, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
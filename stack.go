@@ -34,7 +34,8 @@ func (s *stack) size() int {
 }
 
 // depth-first search
-func (s *stack) dfs(current *Node) {
+// 追加したノードか、同名のノードにまとめた場合はまとめた先のノードを返す。親が見つからない場合は nil を返す
+func (s *stack) dfs(current *Node) *Node {
 	size := s.size()
 	for i := 0; i < size; i++ {
 		parent := s.pop()
@@ -44,13 +45,17 @@ func (s *stack) dfs(current *Node) {
 
 		// for same name on the same hierarchy
 		if child := parent.findChildByText(current.name); child != nil {
+			child.mergeComment(current.comment)
+			child.mergeMeta(current.meta)
+			child.mergeContent(current.content, current.contentInfo)
 			s.push(parent).push(child)
-			return
+			return child
 		}
 
 		parent.addChild(current)
 		current.setParent(parent)
 		s.push(parent).push(current)
-		return
+		return current
 	}
+	return nil
 }
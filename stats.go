@@ -0,0 +1,204 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// WithSummary returns function for outputting "N directories, M files" after each tree in the tree output.
+// Nodes without children are counted as files, or according to WithFileExtensions if it is specified.
+// Nodes hidden by WithMaxDepth / WithMaxChildren / WithFilter / WithExclude are not counted.
+// This option is ignored for JSON, YAML, TOML, Markdown, Mermaid, DOT and HTML output.
+func WithSummary() Option {
+	return func(c *config) {
+		c.summary = true
+	}
+}
+
+func (c *config) summarizer() summarizer {
+	return summarizer{
+		enabled:        c.summary,
+		fileConsiderer: newFileConsiderer(c.fileExtensions),
+	}
+}
+
+// WithSummary で各Rootの後に出力する集計
+type summarizer struct {
+	enabled        bool
+	fileConsiderer *fileConsiderer
+}
+
+func (s summarizer) footer(root *Node) string {
+	if !s.enabled {
+		return ""
+	}
+	st := newStatsCounter(s.fileConsiderer).count(root)
+	return fmt.Sprintf("\n%d directories, %d files\n", st.Dirs, st.Files)
+}
+
+// TreeStats is the statistics of a tree calculated by Stats function.
+type TreeStats struct {
+	// Root is the name of the root node.
+	Root string `json:"root"`
+	// Nodes is the number of all nodes including the root. It is the sum of Dirs and Files.
+	Nodes int `json:"nodes"`
+	Dirs  int `json:"dirs"`
+	Files int `json:"files"`
+	// MaxDepth is the depth of the deepest node. The root is depth 0.
+	MaxDepth int `json:"max_depth"`
+	// WidestLevel is the depth that has the most nodes, and WidestLevelNodes is the number of them.
+	// If several depths have the same number, the shallowest one is reported.
+	WidestLevel      int `json:"widest_level"`
+	WidestLevelNodes int `json:"widest_level_nodes"`
+	// Extensions is the number of files per extension in descending order of the number.
+	Extensions []ExtensionStats `json:"extensions"`
+	// LargestSubtrees is the directories other than the root with the most descendants in descending order of the number.
+	// At most 5 directories are reported.
+	LargestSubtrees []SubtreeStats `json:"largest_subtrees"`
+}
+
+// ExtensionStats is the number of files with an extension.
+type ExtensionStats struct {
+	// Extension is like ".go". It is empty for files without extension.
+	Extension string `json:"extension"`
+	Files     int    `json:"files"`
+}
+
+// SubtreeStats is the size of a subtree.
+type SubtreeStats struct {
+	// Path is the path from the root node to the top of the subtree. The separator is /.
+	Path string `json:"path"`
+	// Descendants is the number of nodes under the top of the subtree.
+	Descendants int `json:"descendants"`
+}
+
+const largestSubtreesNum = 5
+
+// Stats calculates the statistics of the tree whose root is root.
+// Nodes without children are counted as files, or according to WithFileExtensions if it is specified. The other options are ignored.
+// If root is nil, the zero value is returned.
+func Stats(root *Node, options ...Option) TreeStats {
+	if root == nil {
+		return TreeStats{}
+	}
+	// WithFileExtensions 以外は使わないため、不正なオプションのエラーは無視する
+	cfg, _ := newConfig(options)
+	return newStatsCounter(newFileConsiderer(cfg.fileExtensions)).count(root)
+}
+
+func (s TreeStats) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s\n", s.Root)
+	fmt.Fprintf(b, "nodes: %d (%d directories, %d files)\n", s.Nodes, s.Dirs, s.Files)
+	fmt.Fprintf(b, "max depth: %d\n", s.MaxDepth)
+	fmt.Fprintf(b, "widest level: %d (%d nodes)\n", s.WidestLevel, s.WidestLevelNodes)
+
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	if len(s.Extensions) != 0 {
+		fmt.Fprintln(tw, "extensions:")
+		for _, e := range s.Extensions {
+			ext := e.Extension
+			if len(ext) == 0 {
+				ext = "(none)"
+			}
+			fmt.Fprintf(tw, "  %s\t%d\n", ext, e.Files)
+		}
+	}
+	if len(s.LargestSubtrees) != 0 {
+		fmt.Fprintln(tw, "largest subtrees:")
+		for _, st := range s.LargestSubtrees {
+			fmt.Fprintf(tw, "  %s\t%d\n", st.Path, st.Descendants)
+		}
+	}
+	_ = tw.Flush()
+	return b.String()
+}
+
+type statsCounter struct {
+	fileConsiderer *fileConsiderer
+
+	stats      TreeStats
+	levels     []int
+	extensions map[string]int
+	subtrees   []SubtreeStats
+}
+
+func newStatsCounter(fileConsiderer *fileConsiderer) *statsCounter {
+	return &statsCounter{
+		fileConsiderer: fileConsiderer,
+		extensions:     map[string]int{},
+	}
+}
+
+func (sc *statsCounter) count(root *Node) TreeStats {
+	sc.stats = TreeStats{Root: root.name}
+	sc.countNode(root, root.name, 0)
+
+	for depth, n := range sc.levels {
+		if n > sc.stats.WidestLevelNodes {
+			sc.stats.WidestLevel, sc.stats.WidestLevelNodes = depth, n
+		}
+	}
+
+	sc.stats.Extensions = []ExtensionStats{}
+	for ext, n := range sc.extensions {
+		sc.stats.Extensions = append(sc.stats.Extensions, ExtensionStats{Extension: ext, Files: n})
+	}
+	slices.SortFunc(sc.stats.Extensions, func(a, b ExtensionStats) int {
+		return cmp.Or(cmp.Compare(b.Files, a.Files), cmp.Compare(a.Extension, b.Extension))
+	})
+
+	slices.SortStableFunc(sc.subtrees, func(a, b SubtreeStats) int {
+		return cmp.Compare(b.Descendants, a.Descendants)
+	})
+	sc.stats.LargestSubtrees = sc.subtrees[:min(len(sc.subtrees), largestSubtreesNum)]
+	if sc.stats.LargestSubtrees == nil {
+		sc.stats.LargestSubtrees = []SubtreeStats{}
+	}
+	return sc.stats
+}
+
+// 子孫の数を返す
+func (sc *statsCounter) countNode(current *Node, currentPath string, depth int) int {
+	if current.isSummary {
+		return 0
+	}
+
+	sc.stats.Nodes++
+	sc.stats.MaxDepth = max(sc.stats.MaxDepth, depth)
+	if len(sc.levels) <= depth {
+		sc.levels = append(sc.levels, 0)
+	}
+	sc.levels[depth]++
+
+	if sc.fileConsiderer.countsAsFile(current) {
+		sc.stats.Files++
+		sc.extensions[path.Ext(current.name)]++
+	} else {
+		sc.stats.Dirs++
+	}
+
+	// 同数の部分木をツリーの順で並べるため、子孫を数える前に場所を確保しておく
+	idx := -1
+	if depth > 0 && current.hasChild() {
+		idx = len(sc.subtrees)
+		sc.subtrees = append(sc.subtrees, SubtreeStats{Path: currentPath})
+	}
+
+	descendants := 0
+	for _, child := range current.children {
+		if !child.isSummary {
+			descendants += 1 + sc.countNode(child, path.Join(currentPath, child.name), depth+1)
+		}
+	}
+	if idx >= 0 {
+		sc.subtrees[idx].Descendants = descendants
+	}
+	return descendants
+}
@@ -0,0 +1,111 @@
+package gtree_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ddddddO/gtree"
+)
+
+func TestStats(t *testing.T) {
+	root := gtree.NewRoot("repo")
+	cmd := root.Add("cmd")
+	cmd.Add("gtree").Add("main.go")
+	cmd.Add("tool").Add("main.go")
+	root.Add("docs").Add("README.md")
+	root.Add("go.mod")
+	root.Add("Makefile")
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+		want    gtree.TreeStats
+	}{
+		{
+			name: "case(succeeded)",
+			want: gtree.TreeStats{
+				Root:             "repo",
+				Nodes:            10,
+				Dirs:             5,
+				Files:            5,
+				MaxDepth:         3,
+				WidestLevel:      1,
+				WidestLevelNodes: 4,
+				Extensions: []gtree.ExtensionStats{
+					{Extension: ".go", Files: 2},
+					{Extension: "", Files: 1},
+					{Extension: ".md", Files: 1},
+					{Extension: ".mod", Files: 1},
+				},
+				LargestSubtrees: []gtree.SubtreeStats{
+					{Path: "repo/cmd", Descendants: 4},
+					{Path: "repo/cmd/gtree", Descendants: 1},
+					{Path: "repo/cmd/tool", Descendants: 1},
+					{Path: "repo/docs", Descendants: 1},
+				},
+			},
+		},
+		{
+			name:    "case(succeeded/with file extensions)",
+			options: []gtree.Option{gtree.WithFileExtensions([]string{".go"})},
+			want: gtree.TreeStats{
+				Root:             "repo",
+				Nodes:            10,
+				Dirs:             8,
+				Files:            2,
+				MaxDepth:         3,
+				WidestLevel:      1,
+				WidestLevelNodes: 4,
+				Extensions: []gtree.ExtensionStats{
+					{Extension: ".go", Files: 2},
+				},
+				LargestSubtrees: []gtree.SubtreeStats{
+					{Path: "repo/cmd", Descendants: 4},
+					{Path: "repo/cmd/gtree", Descendants: 1},
+					{Path: "repo/cmd/tool", Descendants: 1},
+					{Path: "repo/docs", Descendants: 1},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := gtree.Stats(root, tt.options...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\ngot: \n%+v\nwant: \n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats_nil(t *testing.T) {
+	if got := gtree.Stats(nil); !reflect.DeepEqual(got, gtree.TreeStats{}) {
+		t.Errorf("\ngot: \n%+v\nwant: \n%+v", got, gtree.TreeStats{})
+	}
+}
+
+func TestTreeStats_String(t *testing.T) {
+	root := gtree.NewRoot("repo")
+	root.Add("src").Add("main.go")
+	root.Add("Makefile")
+
+	want := strings.TrimPrefix(`
+repo
+nodes: 4 (2 directories, 2 files)
+max depth: 2
+widest level: 1 (2 nodes)
+extensions:
+  (none)  1
+  .go     1
+largest subtrees:
+  repo/src  1
+`, "\n")
+	if got := gtree.Stats(root).String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
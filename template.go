@@ -0,0 +1,111 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"io"
+	"text/template"
+)
+
+// WithTemplate returns function for rendering each root with t instead of the built-in formats.
+// t is executed once for each root with the *TemplateNode of the root, so it can render a whole document by recursing into Children,
+// or one line per node by ranging over Nodes, e.g. `{{range .Nodes}}{{.Row}}{{"\n"}}{{end}}`.
+func WithTemplate(t *template.Template) Option {
+	return func(c *config) {
+		if t == nil {
+			c.encode = encodeDefault
+			c.template = nil
+			return
+		}
+		c.encode = encodeTemplate
+		c.template = t.Execute
+	}
+}
+
+// TemplateNode is the data passed to the template specified with WithTemplate.
+type TemplateNode struct {
+	// Name is the name of the node.
+	Name string
+	// Branch is the branch of the node in completed tree structure. It is empty for the root.
+	Branch string
+	// Row is the branch and the name of the node, that is, a row of the tree output.
+	Row string
+	// Path is the path from the root node to this node. The separator is / in any OS execution environment.
+	Path string
+	// Level is the level of the node. The root is level 1.
+	Level uint
+	// IsLast reports whether the node is the last of its siblings. It is false for the root.
+	IsLast bool
+	// IsFile reports whether the node is a file.
+	// Nodes without children are considered as files, or according to WithFileExtensions if it is specified.
+	// It is false for the summary nodes.
+	IsFile bool
+	// IsSummary reports whether the node is not a node of the input but the row summarizing the nodes hidden by WithMaxDepth / WithMaxChildren,
+	// e.g. "… 3 more (1 dir, 2 files)". The summary node is the last of Children, so that one line per node renders the same rows as the tree.
+	IsSummary bool
+	// Comment is the annotation of the node.
+	Comment string
+	// Meta is the metadata of the node. It is nil if no metadata has been set.
+	Meta map[string]any
+	// Children is the child nodes.
+	Children []*TemplateNode
+}
+
+// Nodes returns the node and all of its descendants in the order of the tree.
+func (tn *TemplateNode) Nodes() []*TemplateNode {
+	nodes := []*TemplateNode{tn}
+	for _, child := range tn.Children {
+		nodes = append(nodes, child.Nodes()...)
+	}
+	return nodes
+}
+
+func newTemplateSpreaderSimple(execute func(io.Writer, any) error, fileExtensions []string) *templateSpreaderSimple {
+	return &templateSpreaderSimple{
+		execute:        execute,
+		fileConsiderer: newFileConsiderer(fileExtensions),
+	}
+}
+
+// Rootごとに利用者のテンプレートを実行する
+type templateSpreaderSimple struct {
+	execute        func(io.Writer, any) error
+	fileConsiderer *fileConsiderer
+}
+
+func (ts *templateSpreaderSimple) spread(w io.Writer, roots []*Node) error {
+	return spreadDocument(w, ts, roots)
+}
+
+func (*templateSpreaderSimple) header() string { return "" }
+
+func (*templateSpreaderSimple) footer() string { return "" }
+
+func (ts *templateSpreaderSimple) spreadRoot(w io.Writer, root *Node, _ int) error {
+	return ts.execute(w, ts.toTemplateNode(root))
+}
+
+func (ts *templateSpreaderSimple) toTemplateNode(current *Node) *TemplateNode {
+	tn := &TemplateNode{
+		Name:      current.name,
+		Branch:    current.branch(),
+		Row:       current.row(),
+		Path:      current.path(),
+		Level:     current.hierarchy,
+		IsLast:    current.isLastOfHierarchy(),
+		IsFile:    !current.isSummary && ts.fileConsiderer.countsAsFile(current),
+		IsSummary: current.isSummary,
+		Comment:   current.comment,
+		Meta:      current.meta,
+		Children:  make([]*TemplateNode, 0, len(current.children)),
+	}
+	for _, child := range current.children {
+		tn.Children = append(tn.Children, ts.toTemplateNode(child))
+	}
+	return tn
+}
+
+var (
+	_ spreaderSimple         = (*templateSpreaderSimple)(nil)
+	_ documentSpreaderSimple = (*templateSpreaderSimple)(nil)
+)
@@ -0,0 +1,87 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// WithTemplateData returns function for rendering each row of Markdown as text/template with data before it is parsed,
+// e.g. "- {{.Service}}-api". The rows of the code blocks for file contents are rendered too.
+// Each row is rendered on its own, so an action cannot span rows, and a row rendered with a line break is an error.
+// A variable missing in data is an error that names the line of Markdown.
+// If data is nil, the rows are not rendered. This option is ignored for JSON, YAML and TOML input.
+func WithTemplateData(data any) Option {
+	return func(c *config) {
+		c.templateData = data
+	}
+}
+
+func (c *config) rowRenderer() rowRenderer {
+	return rowRenderer{data: c.templateData}
+}
+
+var (
+	errMissingTemplateVariable = errors.New("missing template variable")
+	errTemplateLineBreak       = errors.New("template rendered a line break")
+)
+
+// WithTemplateData で入力の行を描画する
+type rowRenderer struct {
+	data any
+}
+
+// 例: template: row:1:5: executing "row" at <.Service>: map has no entry for key "Service"
+var templateExecErrPattern = regexp.MustCompile(`^template: [^:]*:\d+:(\d+): executing "[^"]*" at <([^>]*)>: (.*)$`)
+
+func (rr rowRenderer) render(row string, line int) (string, error) {
+	if rr.data == nil || !strings.Contains(row, "{{") {
+		return row, nil
+	}
+
+	t, err := template.New("row").Option("missingkey=error").Parse(row)
+	if err != nil {
+		// 例: template: row:1: unclosed action
+		reason := err.Error()
+		if _, after, ok := strings.Cut(reason, "row:1: "); ok {
+			reason = after
+		}
+		return "", newParseErrorAt(fmt.Errorf("template: %s", reason), row, line, strings.Index(row, "{{")+1)
+	}
+
+	b := &strings.Builder{}
+	if err := t.Execute(b, rr.data); err != nil {
+		return "", rr.handleErr(err, row, line)
+	}
+	// 描画した行を複数の行として読むと、行番号が入力と食い違う
+	if strings.Contains(b.String(), "\n") {
+		return "", newParseErrorAt(errTemplateLineBreak, row, line, strings.Index(row, "{{")+1)
+	}
+	return b.String(), nil
+}
+
+func (rr rowRenderer) handleErr(err error, row string, line int) error {
+	m := templateExecErrPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return newParseErrorAt(err, row, line, strings.Index(row, "{{")+1)
+	}
+
+	// text/template の列は0始まり
+	column, _ := strconv.Atoi(m[1])
+	column++
+	if isMissingKey(m[3]) {
+		return newParseErrorAt(fmt.Errorf("%w %s", errMissingTemplateVariable, m[2]), row, line, column)
+	}
+	return newParseErrorAt(fmt.Errorf("template: %s: %s", m[2], m[3]), row, line, column)
+}
+
+func isMissingKey(msg string) bool {
+	return strings.HasPrefix(msg, "map has no entry for key") ||
+		strings.HasPrefix(msg, "nil data; no entry for key") ||
+		strings.HasPrefix(msg, "can't evaluate field")
+}
@@ -8,24 +8,58 @@ import (
 
 // Output outputs a tree to w with r as Markdown format input.
 func Output(w io.Writer, r io.Reader, options ...Option) error {
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
 	return initializeTree(cfg).output(w, r, cfg)
 }
 
 // Mkdir makes directories.
 func Mkdir(r io.Reader, options ...Option) error {
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	cfg.keepTreeShape()
 	return initializeTree(cfg).mkdir(r, cfg)
 }
 
+// MkdirWithResult makes directories like Mkdir, and returns the result of each path handled.
+// The result is returned even if an error is returned, with the paths handled until then.
+func MkdirWithResult(r io.Reader, options ...Option) (MkdirResult, error) {
+	result := MkdirResult{Entries: []MkdirEntry{}}
+	cfg, err := newConfig(options)
+	if err != nil {
+		return result, err
+	}
+	cfg.keepTreeShape()
+	cfg.mkdirResult = &result
+	err = initializeTree(cfg).mkdir(r, cfg)
+	return result, err
+}
+
 // Verify verifies directories.
 func Verify(r io.Reader, options ...Option) error {
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	cfg.keepTreeShape()
 	return initializeTree(cfg).verify(r, cfg)
 }
 
 // Walk executes user-defined function while traversing tree structure recursively.
 func Walk(r io.Reader, callback func(*WalkerNode) error, options ...Option) error {
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
 	return initializeTree(cfg).walk(r, callback, cfg)
 }
+
+// Lint checks r as Markdown format input and returns all problems found in it.
+// Unlike Output/Mkdir/Verify, it does not stop at the first incorrect row.
+func Lint(r io.Reader) []Diagnostic {
+	return newLinter().lint(r)
+}
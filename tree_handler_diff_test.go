@@ -0,0 +1,194 @@
+package gtree_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ddddddO/gtree"
+)
+
+func prepareDiffOld() *gtree.Node {
+	root := gtree.NewRoot("repo")
+	root.Add("cmd").Add("gtree").Add("main.go")
+	root.Add("pkg").Add("util").Add("util.go")
+	root.Add("pkg").Add("legacy").Add("legacy.go")
+	root.Add("docs").Add("README.md")
+	root.Add("Makefile")
+	return root
+}
+
+func prepareDiffNew() *gtree.Node {
+	root := gtree.NewRoot("repo")
+	root.Add("cmd").Add("gtree").Add("main.go")
+	root.Add("cmd").Add("gtree").Add("scan.go")
+	root.Add("internal").Add("util").Add("util.go")
+	root.Add("pkg")
+	root.Add("documents").Add("README.md")
+	root.Add("Makefile")
+	return root
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    *gtree.Node
+		b    *gtree.Node
+		want []gtree.Change
+	}{
+		{
+			name: "case(added, removed, moved and renamed)",
+			a:    prepareDiffOld(),
+			b:    prepareDiffNew(),
+			want: []gtree.Change{
+				{Kind: gtree.ChangeAdded, To: "repo/cmd/gtree/scan.go"},
+				{Kind: gtree.ChangeAdded, To: "repo/internal"},
+				{Kind: gtree.ChangeMoved, From: "repo/pkg/util", To: "repo/internal/util"},
+				{Kind: gtree.ChangeRemoved, From: "repo/pkg/legacy"},
+				{Kind: gtree.ChangeRenamed, From: "repo/docs", To: "repo/documents"},
+			},
+		},
+		{
+			name: "case(no change)",
+			a:    prepareDiffOld(),
+			b:    prepareDiffOld(),
+			want: []gtree.Change{},
+		},
+		{
+			name: "case(renamed root)",
+			a:    gtree.NewRoot("old"),
+			b:    gtree.NewRoot("new"),
+			want: []gtree.Change{
+				{Kind: gtree.ChangeRenamed, From: "old", To: "new"},
+			},
+		},
+		{
+			name: "case(ambiguous leaves are not renamed)",
+			a: func() *gtree.Node {
+				root := gtree.NewRoot("root")
+				root.Add("a")
+				root.Add("b")
+				return root
+			}(),
+			b: func() *gtree.Node {
+				root := gtree.NewRoot("root")
+				root.Add("c")
+				root.Add("d")
+				return root
+			}(),
+			want: []gtree.Change{
+				{Kind: gtree.ChangeRemoved, From: "root/a"},
+				{Kind: gtree.ChangeRemoved, From: "root/b"},
+				{Kind: gtree.ChangeAdded, To: "root/c"},
+				{Kind: gtree.ChangeAdded, To: "root/d"},
+			},
+		},
+		{
+			name: "case(old is nil)",
+			a:    nil,
+			b:    prepareDiffNew(),
+			want: []gtree.Change{
+				{Kind: gtree.ChangeAdded, To: "repo"},
+			},
+		},
+		{
+			name: "case(new is nil)",
+			a:    prepareDiffOld(),
+			b:    nil,
+			want: []gtree.Change{
+				{Kind: gtree.ChangeRemoved, From: "repo"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := gtree.Diff(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\ngot: \n%v\nwant: \n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff_json(t *testing.T) {
+	got, err := json.Marshal(gtree.Diff(prepareDiffOld(), prepareDiffNew()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"kind":"added","to":"repo/cmd/gtree/scan.go"},{"kind":"added","to":"repo/internal"},{"kind":"moved","from":"repo/pkg/util","to":"repo/internal/util"},{"kind":"removed","from":"repo/pkg/legacy"},{"kind":"renamed","from":"repo/docs","to":"repo/documents"}]`
+	if string(got) != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
+
+func TestOutputDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       *gtree.Node
+		b       *gtree.Node
+		options []gtree.Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "case(succeeded)",
+			a:    prepareDiffOld(),
+			b:    prepareDiffNew(),
+			want: strings.TrimPrefix(`
+  repo
+  ├── cmd
+  │   └── gtree
+  │       ├── main.go
++ │       └── scan.go
++ ├── internal
+~ │   └── util (moved from repo/pkg/util)
+  │       └── util.go
+  ├── pkg
+- │   └── legacy
+- │       └── legacy.go
+~ ├── documents (renamed from repo/docs)
+  │   └── README.md
+  └── Makefile
+`, "\n"),
+		},
+		{
+			name:    "case(succeeded/branch format)",
+			a:       gtree.NewRoot("root"),
+			b:       func() *gtree.Node { root := gtree.NewRoot("root"); root.Add("a").Add("b"); return root }(),
+			options: []gtree.Option{gtree.WithBranchFormatIntermedialNode("+--", ":   "), gtree.WithBranchFormatLastNode("+--", "    ")},
+			want: strings.TrimPrefix(`
+  root
++ +-- a
++     +-- b
+`, "\n"),
+		},
+		{
+			name:    "case(not root)",
+			a:       gtree.NewRoot("root").Add("child"),
+			b:       gtree.NewRoot("root"),
+			wantErr: gtree.ErrNotRoot,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			gotErr := gtree.OutputDiff(buf, tt.a, tt.b, tt.options...)
+			if gotErr != tt.wantErr {
+				t.Fatalf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", got, tt.want)
+			}
+		})
+	}
+}
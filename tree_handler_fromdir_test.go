@@ -0,0 +1,196 @@
+package gtree_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ddddddO/gtree"
+)
+
+func TestFromDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proj/.gitignore":            {Data: []byte("# comment\n*.log\n/bin/\nvendor/\n!keep.log\ndocs/**/*.tmp\n")},
+		"proj/.github/ci.yaml":       {},
+		"proj/bin/app":               {},
+		"proj/cmd/app/main.go":       {},
+		"proj/cmd/app/debug.log":     {},
+		"proj/cmd/app/keep.log":      {},
+		"proj/cmd/bin/tool.go":       {},
+		"proj/docs/a/b/c.tmp":        {},
+		"proj/docs/a/b/c.md":         {},
+		"proj/pkg/.gitignore":        {Data: []byte("generated.go\n")},
+		"proj/pkg/generated.go":      {},
+		"proj/pkg/lib.go":            {},
+		"proj/vendor/mod/x.go":       {},
+		"proj/README.md":             {},
+		"proj/empty":                 {Mode: fs.ModeDir},
+		"proj/.git/HEAD":             {},
+		"proj/not_dir_for_error.txt": {},
+	}
+
+	tests := []struct {
+		name    string
+		root    string
+		options []gtree.Option
+		out     out
+	}{
+		{
+			name: "case(succeeded/default)",
+			root: "proj/pkg",
+			out: out{
+				output: strings.TrimPrefix(`
+- pkg
+	- generated.go
+	- lib.go
+`, "\n"),
+			},
+		},
+		{
+			name:    "case(succeeded/hidden)",
+			root:    "proj/pkg",
+			options: []gtree.Option{gtree.WithScanHidden()},
+			out: out{
+				output: strings.TrimPrefix(`
+- pkg
+	- .gitignore
+	- generated.go
+	- lib.go
+`, "\n"),
+			},
+		},
+		{
+			name:    "case(succeeded/max depth and dirs only)",
+			root:    "proj",
+			options: []gtree.Option{gtree.WithScanMaxDepth(2), gtree.WithScanDirsOnly()},
+			out: out{
+				output: strings.TrimPrefix(`
+- proj
+	- bin
+	- cmd
+		- app
+		- bin
+	- docs
+		- a
+	- empty
+	- pkg
+	- vendor
+		- mod
+`, "\n"),
+			},
+		},
+		{
+			name:    "case(succeeded/gitignore)",
+			root:    "proj",
+			options: []gtree.Option{gtree.WithScanGitignore(), gtree.WithScanHidden()},
+			out: out{
+				output: strings.TrimPrefix(`
+- proj
+	- .github
+		- ci.yaml
+	- .gitignore
+	- README.md
+	- cmd
+		- app
+			- keep.log
+			- main.go
+		- bin
+			- tool.go
+	- docs
+		- a
+			- b
+				- c.md
+	- empty
+	- not_dir_for_error.txt
+	- pkg
+		- .gitignore
+		- lib.go
+`, "\n"),
+			},
+		},
+		{
+			name: "case(not exist)",
+			root: "proj/xxx",
+			out: out{
+				err: &fs.PathError{Op: "open", Path: "proj/xxx", Err: fs.ErrNotExist},
+			},
+		},
+		{
+			name: "case(not directory)",
+			root: "proj/not_dir_for_error.txt",
+			out: out{
+				err: errors.New("scan proj/not_dir_for_error.txt: not a directory"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root, gotErr := gtree.FromDir(fsys, tt.root, tt.options...)
+			if gotErr != nil || tt.out.err != nil {
+				if gotErr == nil || tt.out.err == nil || gotErr.Error() != tt.out.err.Error() {
+					t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
+				}
+				return
+			}
+
+			buf := &bytes.Buffer{}
+			if err := gtree.OutputProgrammably(buf, root, gtree.WithEncodeMarkdown()); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.out.output {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", buf.String(), tt.out.output)
+			}
+		})
+	}
+}
+
+func TestFromDir_markdownRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{
+		"proj/a -- b",
+		"proj/C # notes",
+		"proj/x -- y/main.go",
+		`proj/back\#slash`,
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root, err := gtree.FromDir(os.DirFS(dir), "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root, gtree.WithEncodeMarkdown()); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+- proj
+	- C \# notes
+	- a \-- b
+	- back\\#slash
+	- x \-- y
+		- main.go
+`, "\n")
+	if buf.String() != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", buf.String(), want)
+	}
+
+	if err := gtree.Verify(buf, gtree.WithTargetDir(dir), gtree.WithStrictVerify()); err != nil {
+		t.Errorf("\ngot: \n%v\nwant: \nnil", err)
+	}
+}
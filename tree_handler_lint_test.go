@@ -0,0 +1,115 @@
+package gtree_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddddddO/gtree"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name: "case(no problem)",
+			input: strings.TrimSpace(`
+- a
+	- b
+		- c
+	- d`),
+			want: nil,
+		},
+		{
+			name: "case(all problems)",
+			input: strings.TrimPrefix(`
+	- orphan
+- a
+	- b
+	 - c
+	-
+	- b
+		- e
+				- f`, "\n"),
+			want: []string{
+				`1:2: no root node before this node`,
+				`4:2: incorrect input format: mixed tab and space indentation`,
+				`5:3: empty text`,
+				`6:2: duplicate sibling "b" is merged into line 3`,
+				`8:5: no parent node at level 4`,
+			},
+		},
+		{
+			name: "case(inconsistent indentation)",
+			input: strings.TrimSpace(`
+- a
+  - b
+     - c
+    - d`),
+			want: []string{
+				`3:6: incorrect input format: inconsistent indentation`,
+			},
+		},
+		{
+			name: "case(mixed indentation across roots)",
+			input: strings.TrimSpace(`
+- a
+	- b
+- c
+  - d
+- e
+  - f`),
+			want: []string{
+				`4:3: incorrect input format: mixed tab and space indentation`,
+				`6:3: incorrect input format: mixed tab and space indentation`,
+			},
+		},
+		{
+			name: "case(children of broken row are not reported)",
+			input: strings.TrimSpace(`
+- a
+	- b
+	xx- c
+		- d
+	- e`),
+			want: []string{
+				`3:2: incorrect input format`,
+			},
+		},
+		{
+			name: "case(code block)",
+			input: strings.TrimSpace("" +
+				"- a\n" +
+				"\t- main.go\n" +
+				"\t\t```go\n" +
+				"\t\t- not a node\n" +
+				"\t\t```\n" +
+				"\t```\n" +
+				"\t- x\n" +
+				"\t```\n" +
+				"\t- b\n" +
+				"\t\t~~~"),
+			want: []string{
+				`6:2: code block must be indented under a node`,
+				`10:3: unclosed code block`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, d := range gtree.Lint(strings.NewReader(tt.input)) {
+				got = append(got, d.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -2,9 +2,17 @@ package gtree_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ddddddO/gtree"
 )
@@ -144,7 +152,12 @@ func TestMkdir(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotErr := gtree.Mkdir(tt.in.input, tt.in.options...)
+			options := tt.in.options
+			if tt.wantErr == nil {
+				// 作成するケースはカレントディレクトリを汚さない
+				options = append([]gtree.Option{gtree.WithTargetDir(t.TempDir())}, options...)
+			}
+			gotErr := gtree.Mkdir(tt.in.input, options...)
 			if gotErr != nil {
 				t.Log(gotErr.Error())
 				if gotErr.Error() != tt.wantErr.Error() {
@@ -154,3 +167,839 @@ func TestMkdir(t *testing.T) {
 		})
 	}
 }
+
+func TestMkdir_comment(t *testing.T) {
+	dir := t.TempDir()
+	input := strings.NewReader(strings.TrimSpace(`
+- root # the root
+	- main.go # entrypoint
+	- docs -- documents`))
+
+	if err := gtree.Mkdir(input, gtree.WithTargetDir(dir), gtree.WithFileExtensions([]string{".go"})); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"root/main.go", "root/docs"} {
+		if _, err := os.Stat(filepath.Join(dir, p)); err != nil {
+			t.Errorf("\ngot: \n%v\nwant: \n%s exists", err, p)
+		}
+	}
+}
+
+func TestMkdir_ignore_truncation(t *testing.T) {
+	dir := t.TempDir()
+	input := strings.NewReader(strings.TrimSpace(`
+- root
+	- a
+		- b
+	- c`))
+
+	if err := gtree.Mkdir(input, gtree.WithTargetDir(dir), gtree.WithMaxDepth(1), gtree.WithMaxChildren(1)); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"root/a/b", "root/c"} {
+		if _, err := os.Stat(filepath.Join(dir, p)); err != nil {
+			t.Errorf("\ngot: \n%v\nwant: \n%s exists", err, p)
+		}
+	}
+}
+
+func TestMkdir_content(t *testing.T) {
+	input := strings.TrimSpace("" +
+		"- root\n" +
+		"\t- main.go\n" +
+		"\t\t```go\n" +
+		"\t\tpackage main\n" +
+		"\n" +
+		"\t\tfunc main() {}\n" +
+		"\t\t```\n" +
+		"\t- docs\n" +
+		"\t\t- README\n" +
+		"\t\t\t~~~\n" +
+		"\t\t\t# docs\n" +
+		"\t\t\t~~~\n" +
+		"\t- .keep\n" +
+		"\t\t```\n" +
+		"\t\t```\n" +
+		"\t- empty")
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+		want    map[string]string
+	}{
+		{
+			name: "case(succeeded)",
+			want: map[string]string{
+				"root/main.go":     "package main\n\nfunc main() {}\n",
+				"root/docs/README": "# docs\n",
+				"root/.keep":       "",
+			},
+		},
+		{
+			name: "case(succeeded/with file content)",
+			options: []gtree.Option{
+				gtree.WithFileExtensions([]string{".go", ".keep"}),
+				gtree.WithFileContent(func(path string) ([]byte, error) {
+					if path == "root/.keep" {
+						return []byte("generated"), nil
+					}
+					return nil, nil
+				}),
+			},
+			want: map[string]string{
+				"root/main.go":     "package main\n\nfunc main() {}\n",
+				"root/docs/README": "# docs\n",
+				"root/.keep":       "generated",
+			},
+		},
+		{
+			name:    "case(succeeded/massive)",
+			options: []gtree.Option{gtree.WithMassive(context.Background())},
+			want: map[string]string{
+				"root/main.go":     "package main\n\nfunc main() {}\n",
+				"root/docs/README": "# docs\n",
+				"root/.keep":       "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			options := append([]gtree.Option{gtree.WithTargetDir(dir)}, tt.options...)
+			if err := gtree.Mkdir(strings.NewReader(input), options...); err != nil {
+				t.Fatal(err)
+			}
+			for p, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(dir, p))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+				}
+			}
+			if fi, err := os.Stat(filepath.Join(dir, "root/empty")); err != nil || !fi.IsDir() {
+				t.Errorf("\ngot: \n%v\nwant: \nroot/empty is a directory", err)
+			}
+			// 一時ファイルが残っていない
+			entries, err := os.ReadDir(filepath.Join(dir, "root"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 4 {
+				t.Errorf("\ngot: \n%v\nwant: \n4 entries", entries)
+			}
+		})
+	}
+}
+
+func TestMkdir_filePermission(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "root"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "root", "c.go"), []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	input := "" +
+		"- root\n" +
+		"\t- a.go\n" +
+		"\t- b.go\n" +
+		"\t\t```go\n" +
+		"\t\tpackage b\n" +
+		"\t\t```\n" +
+		"\t- c.go\n" +
+		"\t\t```go\n" +
+		"\t\tpackage c\n" +
+		"\t\t```\n"
+	if err := gtree.Mkdir(strings.NewReader(input),
+		gtree.WithTargetDir(dir),
+		gtree.WithFileExtensions([]string{".go"}),
+		gtree.WithMkdirMode(gtree.MkdirOverwrite),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	perm := func(name string) os.FileMode {
+		fi, err := os.Stat(filepath.Join(dir, "root", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi.Mode().Perm()
+	}
+	// 内容の有無によらず同じ権限で作り、上書きしたファイルは元の権限のまま
+	if got, want := perm("b.go"), perm("a.go"); got != want {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", got, want)
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "created.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := f.Stat()
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := perm("a.go"), fi.Mode().Perm(); got != want {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", got, want)
+	}
+	if got, want := perm("c.go"), os.FileMode(0o600); got != want {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", got, want)
+	}
+
+	// 一時ファイルから置き換えるため、一時ファイルは残らない
+	entries, err := os.ReadDir(filepath.Join(dir, "root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"a.go", "b.go", "c.go"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", names, want)
+	}
+}
+
+func TestMkdir_content_error(t *testing.T) {
+	errContent := errors.New("failed to generate")
+
+	tests := []struct {
+		name    string
+		in      in
+		wantErr string
+	}{
+		{
+			name: "case(unclosed code block)",
+			in: in{
+				input: strings.NewReader("- root\n\t- a.go\n\t\t```\n\t\tpackage a\n"),
+			},
+			wantErr: "3:3: unclosed code block: \"\\t\\t```\"",
+		},
+		{
+			name: "case(code block not under node)",
+			in: in{
+				input: strings.NewReader("- root\n\t- a.go\n\t```\n\t```\n"),
+			},
+			wantErr: "3:2: code block must be indented under a node: \"\\t```\"",
+		},
+		{
+			name: "case(content less indented)",
+			in: in{
+				input: strings.NewReader("- root\n\t- a.go\n\t\t```\n\tpackage a\n\t\t```\n"),
+			},
+			wantErr: "4:2: code block content is less indented than the opening fence: \"\\tpackage a\"",
+		},
+		{
+			name: "case(code block under directory)",
+			in: in{
+				input: strings.NewReader("- root\n\t- a\n\t\t```\n\t\t```\n\t\t- b\n"),
+			},
+			wantErr: "code block under a node with children: root/a",
+		},
+		{
+			name: "case(file content error)",
+			in: in{
+				input: strings.NewReader("- root\n\t- a.go\n"),
+				options: []gtree.Option{
+					gtree.WithFileExtensions([]string{".go"}),
+					gtree.WithFileContent(func(string) ([]byte, error) { return nil, errContent }),
+				},
+			},
+			wantErr: "root/a.go: failed to generate",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			options := append([]gtree.Option{gtree.WithTargetDir(t.TempDir())}, tt.in.options...)
+			gotErr := gtree.Mkdir(tt.in.input, options...)
+			if gotErr == nil || gotErr.Error() != tt.wantErr {
+				t.Errorf("\ngotErr: \n%v\nwantErr: \n%s", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMkdir_templateData(t *testing.T) {
+	input := strings.TrimSpace("" +
+		"- {{.Service}}-api\n" +
+		"\t- main.go\n" +
+		"\t\t```go\n" +
+		"\t\t// Package main is the {{.Service}} service.\n" +
+		"\t\tpackage main\n" +
+		"\t\t```\n" +
+		"\t- {{.Owner}}")
+
+	t.Run("case(succeeded)", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		data := map[string]any{"Service": "billing", "Owner": "team"}
+		if err := gtree.Mkdir(strings.NewReader(input), gtree.WithTargetDir(dir), gtree.WithTemplateData(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dir, "billing-api", "main.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "// Package main is the billing service.\npackage main\n"
+		if string(got) != want {
+			t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "billing-api", "team")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("case(missing template variable in content)", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		data := map[string]any{"Owner": "team"}
+		input := strings.Replace(input, "{{.Service}}-api", "api", 1)
+		gotErr := gtree.Mkdir(strings.NewReader(input), gtree.WithTargetDir(dir), gtree.WithTemplateData(data))
+
+		var pe *gtree.ParseError
+		if !errors.As(gotErr, &pe) || pe.Line != 4 {
+			t.Fatalf("\ngotErr: \n%v\nwantErr: \nParseError at line 4", gotErr)
+		}
+		wantErr := `4:28: missing template variable .Service: "\t\t// Package main is the {{.Service}} service."`
+		if gotErr.Error() != wantErr {
+			t.Errorf("\ngotErr: \n%v\nwantErr: \n%s", gotErr, wantErr)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("\ngot: \n%v\nwant: \nnothing is made", entries)
+		}
+	})
+}
+
+func TestMkdir_mode(t *testing.T) {
+	input := strings.TrimSpace("" +
+		"- root\n" +
+		"\t- a\n" +
+		"\t\t- x.go\n" +
+		"\t\t\t```\n" +
+		"\t\t\tnew\n" +
+		"\t\t\t```\n" +
+		"\t\t- y.go\n" +
+		"\t- b\n" +
+		"- other\n" +
+		"\t- c")
+
+	// root/a/x.go と root/b が既に存在する
+	prepare := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "root", "a"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(filepath.Join(dir, "root", "b"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "root", "a", "x.go"), []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	tests := []struct {
+		name       string
+		options    []gtree.Option
+		wantReport gtree.MkdirReport
+		wantX      string
+		wantErr    error
+	}{
+		{
+			name:       "case(fail)",
+			wantReport: gtree.MkdirReport{Created: []string{}, Present: []string{}, Overwritten: []string{}},
+			wantX:      "old\n",
+			wantErr:    gtree.ErrExistPath,
+		},
+		{
+			name:    "case(skip)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirSkip)},
+			wantReport: gtree.MkdirReport{
+				Created:     []string{"other", "other/c"},
+				Present:     []string{"root"},
+				Overwritten: []string{},
+			},
+			wantX: "old\n",
+		},
+		{
+			name:    "case(merge)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirMerge)},
+			wantReport: gtree.MkdirReport{
+				Created:     []string{"root/a/y.go", "other", "other/c"},
+				Present:     []string{"root", "root/a", "root/a/x.go", "root/b"},
+				Overwritten: []string{},
+			},
+			wantX: "old\n",
+		},
+		{
+			name:    "case(overwrite)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirOverwrite)},
+			wantReport: gtree.MkdirReport{
+				Created:     []string{"root/a/y.go", "other", "other/c"},
+				Present:     []string{"root", "root/a", "root/b"},
+				Overwritten: []string{"root/a/x.go"},
+			},
+			wantX: "new\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := prepare(t)
+			report := gtree.MkdirReport{}
+			options := append([]gtree.Option{
+				gtree.WithTargetDir(dir),
+				gtree.WithFileExtensions([]string{".go"}),
+				gtree.WithMkdirReport(&report),
+			}, tt.options...)
+
+			gotErr := gtree.Mkdir(strings.NewReader(input), options...)
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(report, tt.wantReport) {
+				t.Errorf("\ngot: \n%+v\nwant: \n%+v", report, tt.wantReport)
+			}
+			gotX, err := os.ReadFile(filepath.Join(dir, "root", "a", "x.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(gotX) != tt.wantX {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", gotX, tt.wantX)
+			}
+		})
+	}
+}
+
+func TestMkdir_mode_massive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "root1", "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	input := strings.TrimSpace(`
+- root1
+	- a
+	- b
+- root2
+	- c`)
+
+	report := gtree.MkdirReport{}
+	err := gtree.Mkdir(strings.NewReader(input),
+		gtree.WithTargetDir(dir),
+		gtree.WithMkdirMode(gtree.MkdirMerge),
+		gtree.WithMkdirReport(&report),
+		gtree.WithMassive(context.Background()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ワーカーの処理順は保証されないため並べ替えて比較する
+	slices.Sort(report.Created)
+	slices.Sort(report.Present)
+	want := gtree.MkdirReport{
+		Created:     []string{"root1/b", "root2", "root2/c"},
+		Present:     []string{"root1", "root1/a"},
+		Overwritten: []string{},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("\ngot: \n%+v\nwant: \n%+v", report, want)
+	}
+}
+
+func TestMkdir_mode_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options []gtree.Option
+		wantErr string
+	}{
+		{
+			name:    "case(file exists as directory)",
+			input:   "- root\n\t- a.go",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirMerge), gtree.WithFileExtensions([]string{".go"})},
+			wantErr: "path already exists as a directory: root/a.go",
+		},
+		{
+			name:    "case(directory exists as file)",
+			input:   "- root\n\t- b\n\t\t- c",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirOverwrite)},
+			wantErr: "path already exists as a file: root/b",
+		},
+		{
+			name:    "case(invalid mode)",
+			input:   "- root",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirMode(100))},
+			wantErr: "invalid mkdir mode: 100",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// root/a.go はディレクトリ、root/b はファイルとして既に存在する
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "root", "a.go"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "root", "b"), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			options := append([]gtree.Option{gtree.WithTargetDir(dir)}, tt.options...)
+			gotErr := gtree.Mkdir(strings.NewReader(tt.input), options...)
+			if gotErr == nil || gotErr.Error() != tt.wantErr {
+				t.Errorf("\ngotErr: \n%v\nwantErr: \n%s", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMkdir_rollback(t *testing.T) {
+	// root/zz.go がディレクトリとして存在するため、途中で失敗する
+	input := strings.TrimSpace("" +
+		"- root\n" +
+		"\t- f.go\n" +
+		"\t\t```\n" +
+		"\t\tnew\n" +
+		"\t\t```\n" +
+		"\t- n1\n" +
+		"\t\t- n2\n" +
+		"\t- keep\n" +
+		"\t- zz.go")
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+		wantErr string
+	}{
+		{
+			name:    "case(merge)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirMerge)},
+			wantErr: "path already exists as a directory: root/zz.go: rolled back: removed 2 paths",
+		},
+		{
+			name:    "case(overwrite)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirOverwrite)},
+			wantErr: "path already exists as a directory: root/zz.go: rolled back: removed 2 paths, restored 1 file",
+		},
+		{
+			name:    "case(overwrite/massive)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirOverwrite), gtree.WithMassive(context.Background())},
+			wantErr: "path already exists as a directory: root/zz.go: rolled back: removed 2 paths, restored 1 file",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for _, d := range []string{"root/keep", "root/zz.go"} {
+				if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(filepath.Join(dir, "root", "f.go"), []byte("orig\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			options := append([]gtree.Option{
+				gtree.WithTargetDir(dir),
+				gtree.WithFileExtensions([]string{".go"}),
+				gtree.WithMkdirRollback(),
+			}, tt.options...)
+			gotErr := gtree.Mkdir(strings.NewReader(input), options...)
+			if gotErr == nil || gotErr.Error() != tt.wantErr {
+				t.Errorf("\ngotErr: \n%v\nwantErr: \n%s", gotErr, tt.wantErr)
+			}
+			var rerr *gtree.MkdirRollbackError
+			if !errors.As(gotErr, &rerr) || rerr.RollbackErr != nil {
+				t.Fatalf("\ngotErr: \n%#v\nwantErr: \nrolled back MkdirRollbackError", gotErr)
+			}
+			if want := []string{"root/n1/n2", "root/n1"}; !reflect.DeepEqual(rerr.Removed, want) {
+				t.Errorf("\ngot: \n%v\nwant: \n%v", rerr.Removed, want)
+			}
+
+			var got []string
+			_ = filepath.WalkDir(dir, func(path string, _ os.DirEntry, _ error) error {
+				rel, _ := filepath.Rel(dir, path)
+				got = append(got, filepath.ToSlash(rel))
+				return nil
+			})
+			want := []string{".", "root", "root/f.go", "root/keep", "root/zz.go"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("\ngot: \n%v\nwant: \n%v", got, want)
+			}
+			fi, err := os.Stat(filepath.Join(dir, "root", "f.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := os.ReadFile(filepath.Join(dir, "root", "f.go"))
+			if string(content) != "orig\n" || fi.Mode().Perm() != 0o600 {
+				t.Errorf("\ngot: \n%s(%v)\nwant: \norig\n(-rw-------)", content, fi.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestMkdir_rollback_massive(t *testing.T) {
+	t.Parallel()
+
+	// 作成先のディレクトリも作ったものとして削除される
+	dir := filepath.Join(t.TempDir(), "x", "y")
+	errContent := errors.New("failed to generate")
+	var input strings.Builder
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&input, "- root%d\n\t- a\n\t\t- b.go\n", i)
+	}
+
+	gotErr := gtree.Mkdir(strings.NewReader(input.String()),
+		gtree.WithTargetDir(dir),
+		gtree.WithFileExtensions([]string{".go"}),
+		gtree.WithFileContent(func(path string) ([]byte, error) {
+			if path == "root20/a/b.go" {
+				return nil, errContent
+			}
+			return nil, nil
+		}),
+		gtree.WithMkdirRollback(),
+		gtree.WithMassive(context.Background()),
+	)
+	if !errors.Is(gotErr, errContent) {
+		t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, errContent)
+	}
+	if _, err := os.Stat(filepath.Dir(dir)); !os.IsNotExist(err) {
+		t.Errorf("\ngot: \n%v\nwant: \n%s is removed", err, filepath.Dir(dir))
+	}
+}
+
+func TestMkdir_rollback_nothing_to_undo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "root"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gotErr := gtree.Mkdir(strings.NewReader("- root\n\t- a"), gtree.WithTargetDir(dir), gtree.WithMkdirRollback())
+	if !errors.Is(gotErr, gtree.ErrExistPath) {
+		t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, gtree.ErrExistPath)
+	}
+	if want := "path already exists: rolled back: removed 0 paths"; gotErr == nil || gotErr.Error() != want {
+		t.Errorf("\ngotErr: \n%v\nwantErr: \n%s", gotErr, want)
+	}
+}
+
+func TestMkdirWithResult(t *testing.T) {
+	input := strings.TrimSpace(`
+- root
+	- a
+		- x.go
+	- b
+- other
+	- y.go`)
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+		want    []gtree.MkdirEntry
+		wantErr error
+	}{
+		{
+			name: "case(succeeded)",
+			want: []gtree.MkdirEntry{
+				{Path: "root", Status: gtree.MkdirSkipped},
+				{Path: "root/a", Status: gtree.MkdirCreatedDir},
+				{Path: "root/a/x.go", Status: gtree.MkdirCreatedFile, IsFile: true},
+				{Path: "root/b", Status: gtree.MkdirFailed, Err: errors.New("path already exists as a file: root/b")},
+			},
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirMerge)},
+			wantErr: errors.New("path already exists as a file: root/b"),
+		},
+		{
+			name: "case(fail)",
+			want: []gtree.MkdirEntry{
+				{Path: "root", Status: gtree.MkdirFailed, Err: gtree.ErrExistPath},
+			},
+			wantErr: gtree.ErrExistPath,
+		},
+		{
+			name:    "case(skip)",
+			options: []gtree.Option{gtree.WithMkdirMode(gtree.MkdirSkip)},
+			want: []gtree.MkdirEntry{
+				{Path: "root", Status: gtree.MkdirSkipped},
+				{Path: "other", Status: gtree.MkdirCreatedDir},
+				{Path: "other/y.go", Status: gtree.MkdirCreatedFile, IsFile: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// root/b はファイルとして既に存在する
+			dir := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dir, "root"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "root", "b"), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			options := append([]gtree.Option{gtree.WithTargetDir(dir), gtree.WithFileExtensions([]string{".go"})}, tt.options...)
+			got, gotErr := gtree.MkdirWithResult(strings.NewReader(input), options...)
+			if fmt.Sprint(gotErr) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.wantErr)
+			}
+			if fmt.Sprintf("%+v", got.Entries) != fmt.Sprintf("%+v", tt.want) {
+				t.Errorf("\ngot: \n%+v\nwant: \n%+v", got.Entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestMkdirWithResult_massive(t *testing.T) {
+	t.Parallel()
+
+	const rootsNum = 5
+	var input strings.Builder
+	for i := 0; i < rootsNum; i++ {
+		fmt.Fprintf(&input, "- root%d\n\t- b.go\n", i)
+	}
+
+	// 全てのワーカーが失敗するように、全てのRootのファイルに到達するまで待つ
+	errContent := errors.New("failed to generate")
+	arrived := &sync.WaitGroup{}
+	arrived.Add(rootsNum)
+	content := func(string) ([]byte, error) {
+		arrived.Done()
+		done := make(chan struct{})
+		go func() {
+			arrived.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+		}
+		return nil, errContent
+	}
+
+	got, gotErr := gtree.MkdirWithResult(strings.NewReader(input.String()),
+		gtree.WithTargetDir(t.TempDir()),
+		gtree.WithFileExtensions([]string{".go"}),
+		gtree.WithFileContent(content),
+		gtree.WithMassive(context.Background()),
+	)
+	if !errors.Is(gotErr, errContent) {
+		t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, errContent)
+	}
+
+	failed := got.Failed()
+	paths := []string{}
+	for _, e := range failed {
+		if !errors.Is(e.Err, errContent) || !e.IsFile {
+			t.Errorf("\ngot: \n%+v\nwant: \nfailed file with %v", e, errContent)
+		}
+		paths = append(paths, e.Path)
+	}
+	slices.Sort(paths)
+	want := []string{"root0/b.go", "root1/b.go", "root2/b.go", "root3/b.go", "root4/b.go"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", paths, want)
+	}
+}
+
+func TestMkdir_onCreate(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	err := gtree.Mkdir(strings.NewReader("- root\n\t- a\n\t\t- x.go\n- other"),
+		gtree.WithTargetDir(t.TempDir()),
+		gtree.WithFileExtensions([]string{".go"}),
+		gtree.WithOnCreate(func(path string, isFile bool) {
+			got = append(got, fmt.Sprintf("%s:%t", path, isFile))
+		}),
+		gtree.WithMassive(context.Background()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ワーカーの処理順は保証されないため並べ替えて比較する
+	slices.Sort(got)
+	want := []string{"other:false", "root/a/x.go:true", "root/a:false", "root:false"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", got, want)
+	}
+}
+
+func TestMkdirResult_json(t *testing.T) {
+	t.Parallel()
+
+	result := gtree.MkdirResult{Entries: []gtree.MkdirEntry{
+		{Path: "root", Status: gtree.MkdirCreatedDir},
+		{Path: "root/a.go", Status: gtree.MkdirCreatedFile, IsFile: true},
+		{Path: "root/b", Status: gtree.MkdirSkipped},
+		{Path: "root/c.go", Status: gtree.MkdirOverwritten, IsFile: true},
+		{Path: "root/d", Status: gtree.MkdirFailed, Err: errors.New("permission denied")},
+	}}
+	got, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"entries":[` +
+		`{"path":"root","status":"created_dir","is_file":false},` +
+		`{"path":"root/a.go","status":"created_file","is_file":true},` +
+		`{"path":"root/b","status":"skipped","is_file":false},` +
+		`{"path":"root/c.go","status":"overwritten","is_file":true},` +
+		`{"path":"root/d","status":"failed","is_file":false,"error":"permission denied"}]}`
+	if string(got) != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
+
+func TestMkdirResult_report(t *testing.T) {
+	t.Parallel()
+
+	result := gtree.MkdirResult{Entries: []gtree.MkdirEntry{
+		{Path: "root", Status: gtree.MkdirSkipped},
+		{Path: "root/a", Status: gtree.MkdirCreatedDir},
+		{Path: "root/a/b.go", Status: gtree.MkdirCreatedFile, IsFile: true},
+		{Path: "root/c.go", Status: gtree.MkdirOverwritten, IsFile: true},
+		{Path: "root/d", Status: gtree.MkdirFailed, Err: errors.New("permission denied")},
+	}}
+	want := gtree.MkdirReport{
+		Created:     []string{"root/a", "root/a/b.go"},
+		Present:     []string{"root"},
+		Overwritten: []string{"root/c.go"},
+	}
+	if got := result.Report(); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot: \n%+v\nwant: \n%+v", got, want)
+	}
+}
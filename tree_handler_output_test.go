@@ -10,10 +10,12 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/ddddddO/gtree"
 	tu "github.com/ddddddO/gtree/testutil"
+	"github.com/fatih/color"
 	"go.uber.org/goleak"
 )
 
@@ -81,6 +83,27 @@ func TestOutput_dryrun_detecting_goroutineleak(t *testing.T) {
 	}
 }
 
+func TestOutput_massive_parse_error_line(t *testing.T) {
+	r := strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+- c
+	- d
+
+- e
+	- f
+	 - g`))
+	gotErr := gtree.Output(io.Discard, r, gtree.WithMassive(context.Background()))
+
+	var pe *gtree.ParseError
+	if !errors.As(gotErr, &pe) {
+		t.Fatalf("\ngotErr: \n%v\nwant: \n%T", gotErr, pe)
+	}
+	if pe.Line != 8 || pe.Column != 2 {
+		t.Errorf("\ngot: \n%d:%d\nwant: \n%d:%d", pe.Line, pe.Column, 8, 2)
+	}
+}
+
 type in struct {
 	input   io.Reader
 	options []gtree.Option
@@ -476,7 +499,7 @@ a
 			},
 			out: out{
 				output: "",
-				err:    gtree.ExportErrEmptyText,
+				err:    gtree.ExportErrEmptyText("	-", 2),
 			},
 		},
 		/*{
@@ -631,7 +654,7 @@ a
 			},
 			out: out{
 				output: "",
-				err:    gtree.ExportErrIncorrectFormat("	 - b"),
+				err:    errors.New(`2:2: incorrect input format: mixed tab and space indentation: "\t - b"`),
 			},
 		},
 		{
@@ -769,261 +792,1881 @@ children = []
 				err: nil,
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		tt := tt
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			out := &bytes.Buffer{}
-			gotErr := gtree.Output(out, tt.in.input, tt.in.options...)
-			gotOutput := out.String()
-
-			if gotOutput != tt.out.output {
-				t.Errorf("\ngot: \n%s\nwant: \n%s", gotOutput, tt.out.output)
-			}
-			if gotErr != nil {
-				if gotErr.Error() != tt.out.err.Error() {
-					t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
-				}
-			}
-
-			if file, ok := tt.in.input.(*os.File); ok {
-				file.Close()
-			}
-		})
-	}
-}
-
-func TestOutput_encodeJSON(t *testing.T) {
-	tests := []struct {
-		name string
-		in   in
-		out  out
-	}{
 		{
-			name: "case(tab spaces & multi root & output json)",
+			name: "case(succeeded/encode markdown)",
 			in: in{
 				input: strings.NewReader(strings.TrimSpace(`
+* a
+    + b
+        + c
+    + d
+# x
+- y`)),
+				options: []gtree.Option{
+					gtree.WithEncodeMarkdown(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
 - a
-	- i
-		- u
-			- k
-			- kk
-		- t
-	- e
-		- o
-	- g
+	- b
+		- c
+	- d
+- x
+	- y
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			// 複数Rootブロックを指定すべきだが、実装上、出力の順番が保証されないため1Rootで実施
+			name: "case(succeeded/when massive root and markdown)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
 - a
-	- i
-		- u
-			- k
-			- kk
-		- t
-	- e
-		- o
-	- g`)),
-				options: []gtree.Option{gtree.WithEncodeJSON()},
+  - b
+    - c`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithEncodeMarkdown(),
+				},
 			},
 			out: out{
 				output: strings.TrimPrefix(`
-{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
-{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+- a
+	- b
+		- c
 `, "\n"),
 				err: nil,
 			},
 		},
 		{
-			name: "case(indent 2spaces & output json)",
+			name: "case(succeeded/encode markdown with bullet and indent)",
 			in: in{
 				input: strings.NewReader(strings.TrimSpace(`
 - a
-  - i
-    - u
-      - k
-      - kk
-    - t
-  - e
-    - o
-  - g`)),
-				options: []gtree.Option{gtree.WithEncodeJSON()},
+	+ b
+		* c
+	- d`)),
+				options: []gtree.Option{
+					gtree.WithEncodeMarkdown(),
+					gtree.WithMarkdownBullet("*"),
+					gtree.WithMarkdownIndent(2),
+				},
 			},
 			out: out{
 				output: strings.TrimPrefix(`
-{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+* a
+  * b
+    * c
+  * d
 `, "\n"),
 				err: nil,
 			},
 		},
 		{
-			name: "case(indent 4spaces & output json)",
+			name: "case(succeeded/encode markdown with sort)",
 			in: in{
 				input: strings.NewReader(strings.TrimSpace(`
 - a
-    - i
-        - u
-            - k
-            - kk
-        - t
-    - e
-        - o
-    - g`)),
-				options: []gtree.Option{gtree.WithEncodeJSON()},
+	- d
+	- b
+		- z
+		- c
+	- a`)),
+				options: []gtree.Option{
+					gtree.WithEncodeMarkdown(),
+					gtree.WithSort(gtree.SortByName),
+				},
 			},
 			out: out{
 				output: strings.TrimPrefix(`
-{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+- a
+	- a
+	- b
+		- c
+		- z
+	- d
 `, "\n"),
 				err: nil,
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		tt := tt
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			out := &bytes.Buffer{}
-			gotErr := gtree.Output(out, tt.in.input, tt.in.options...)
-			gotOutput := out.String()
-
-			if gotOutput != tt.out.output {
-				t.Errorf("\ngot: \n%s\nwant: \n%s", gotOutput, tt.out.output)
-			}
-			if gotErr != tt.out.err {
-				t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
-			}
-		})
-	}
-}
-
-func TestOutput_encodeTOML(t *testing.T) {
-	tests := []struct {
-		name string
-		in   in
-		out  out
-	}{
 		{
-			name: "case(succeeded/tab spaces & multi root & output toml)",
+			name: "case(succeeded/sort)",
 			in: in{
 				input: strings.NewReader(strings.TrimSpace(`
 - a
-	- i
-		- u
-			- k
-			- kk
-		- t
-	- e
-		- o
-	- g
-- a
-	- i
-		- u
-			- k
-			- kk
-		- t
-	- e
-		- o
-	- g`)),
-				options: []gtree.Option{gtree.WithEncodeTOML()},
+	- d
+	- b
+		- z
+		- c
+	- a`)),
+				options: []gtree.Option{
+					gtree.WithSort(gtree.SortByName),
+				},
 			},
 			out: out{
 				output: strings.TrimPrefix(`
-value = 'a'
-
-[[children]]
-value = 'i'
-
-[[children.children]]
-value = 'u'
-
-[[children.children.children]]
-value = 'k'
-children = []
-
-[[children.children.children]]
-value = 'kk'
-children = []
-
-[[children.children]]
-value = 't'
-children = []
-
-[[children]]
-value = 'e'
-
-[[children.children]]
-value = 'o'
-children = []
-
-[[children]]
-value = 'g'
-children = []
-value = 'a'
-
-[[children]]
-value = 'i'
-
-[[children.children]]
-value = 'u'
-
-[[children.children.children]]
-value = 'k'
-children = []
-
-[[children.children.children]]
-value = 'kk'
-children = []
-
-[[children.children]]
-value = 't'
-children = []
-
-[[children]]
-value = 'e'
-
-[[children.children]]
-value = 'o'
-children = []
-
-[[children]]
-value = 'g'
-children = []
+a
+├── a
+├── b
+│   ├── c
+│   └── z
+└── d
 `, "\n"),
 				err: nil,
 			},
 		},
 		{
-			name: "case(succeeded/indent 2spaces & output toml)",
+			name: "case(succeeded/when massive root and sort)",
 			in: in{
 				input: strings.NewReader(strings.TrimSpace(`
 - a
-  - i
-    - u
-      - k
-      - kk
-    - t
-  - e
-    - o
-  - g`)),
-				options: []gtree.Option{gtree.WithEncodeTOML()},
+	- d
+	- b
+		- z
+		- c
+	- a`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithSort(gtree.SortByName),
+				},
 			},
 			out: out{
 				output: strings.TrimPrefix(`
-value = 'a'
-
-[[children]]
-value = 'i'
-
-[[children.children]]
-value = 'u'
-
-[[children.children.children]]
-value = 'k'
-children = []
-
+a
+├── a
+├── b
+│   ├── c
+│   └── z
+└── d
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode mermaid)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a "quoted" #1
+	- b
+		- x
+	- <c>
+		- x
+- d`)),
+				options: []gtree.Option{
+					gtree.WithEncodeMermaid(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+graph TD
+    n0["a #quot;quoted#quot; #35;1"]
+    n0 --> n0_0["b"]
+    n0_0 --> n0_0_0["x"]
+    n0 --> n0_1["#lt;c#gt;"]
+    n0_1 --> n0_1_0["x"]
+    n1["d"]
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode dot)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a "quoted"
+	- b
+		- x
+	- c\d
+		- x
+- d`)),
+				options: []gtree.Option{
+					gtree.WithEncodeDOT(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+digraph gtree {
+    n0 [label="a \"quoted\""];
+    n0_0 [label="b"];
+    n0 -> n0_0;
+    n0_0_0 [label="x"];
+    n0_0 -> n0_0_0;
+    n0_1 [label="c\\d"];
+    n0 -> n0_1;
+    n0_1_0 [label="x"];
+    n0_1 -> n0_1_0;
+    n1 [label="d"];
+}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			// 複数Rootブロックを指定すべきだが、実装上、出力の順番が保証されないため1Rootで実施
+			name: "case(succeeded/when massive root and mermaid)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithEncodeMermaid(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+graph TD
+    n0["a"]
+    n0 --> n0_0["b"]
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and dot)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithEncodeDOT(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+digraph gtree {
+    n0 [label="a"];
+    n0_0 [label="b"];
+    n0 -> n0_0;
+}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode html)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a & <b>
+	- main.go
+	- "pkg"
+		- x.go
+	- empty
+- c`)),
+				options: []gtree.Option{
+					gtree.WithEncodeHTML(),
+					gtree.WithFileExtensions([]string{".go"}),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gtree</title>
+</head>
+<body>
+<div class="gtree">
+<details class="dir" open><summary>a &amp; &lt;b&gt;</summary>
+  <div class="file">main.go</div>
+  <details class="dir"><summary>&#34;pkg&#34;</summary>
+    <div class="file">x.go</div>
+  </details>
+  <div class="dir">empty</div>
+</details>
+<div class="dir">c</div>
+</div>
+</body>
+</html>
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode html with stylesheet)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b`)),
+				options: []gtree.Option{
+					gtree.WithEncodeHTML(),
+					gtree.WithHTMLStylesheet(".gtree { color: red; }"),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gtree</title>
+<style>
+.gtree { color: red; }
+</style>
+</head>
+<body>
+<div class="gtree">
+<details class="dir" open><summary>a</summary>
+  <div class="dir">b</div>
+</details>
+</div>
+</body>
+</html>
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and html)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b.md`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithEncodeHTML(),
+					gtree.WithFileExtensions([]string{".md"}),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gtree</title>
+</head>
+<body>
+<div class="gtree">
+<details class="dir" open><summary>a</summary>
+  <div class="file">b.md</div>
+</details>
+</div>
+</body>
+</html>
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/branch style ascii)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c
+	- d`)),
+				options: []gtree.Option{
+					gtree.WithBranchStyle("ascii"),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+|-- b
+|   `+"`"+`-- c
+`+"`"+`-- d
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/branch style indent-only and overwritten last node)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c
+	- d`)),
+				options: []gtree.Option{
+					gtree.WithBranchStyle("indent-only"),
+					gtree.WithBranchFormatLastNode("*  ", "    "),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+    b
+    *   c
+*   d
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and branch style double)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+	- c`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithBranchStyle("double"),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+╠══ b
+╚══ c
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(unknown branch style)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b`)),
+				options: []gtree.Option{
+					gtree.WithBranchStyle("unknown"),
+				},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`unknown branch style: "unknown"`),
+			},
+		},
+		{
+			name: "case(succeeded/comment)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo # the repository
+	- cmd
+		- main.go # entrypoint
+	- go.mod -- module definition
+	- C#
+- x
+	- y`)),
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo             # the repository
+├── cmd
+│   └── main.go  # entrypoint
+├── go.mod       # module definition
+└── C#
+x
+└── y
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/comment of same name node is merged)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+# repo
+- cmd
+	- main.go
+- cmd # commands
+	- main.go # entrypoint
+- cmd # ignored`)),
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+└── cmd          # commands
+    └── main.go  # entrypoint
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and comment)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- main.go # entrypoint
+	- go.mod`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+├── main.go  # entrypoint
+└── go.mod
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode markdown with comment)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- main.go   --   entrypoint`)),
+				options: []gtree.Option{
+					gtree.WithEncodeMarkdown(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+- repo
+	- main.go # entrypoint
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode markdown with meta)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo {owner: core}
+	- api.go {status: "in review, blocked", owner: team-a} # handlers
+	- web {owner: team-b}`)),
+				options: []gtree.Option{
+					gtree.WithEncodeMarkdown(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+- repo {owner: core}
+	- api.go {owner: team-a, status: "in review, blocked"} # handlers
+	- web {owner: team-b}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/meta is not output in tree)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo {owner: core}
+	- api.go {owner: team-a}`)),
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+└── api.go
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/decode tree with comment)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+repo             # the repository
+├── cmd
+│   └── main.go  # entrypoint
+└── go.mod`)),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+					gtree.WithEncodeJSON(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"repo","comment":"the repository","children":[{"value":"cmd","children":[{"value":"main.go","comment":"entrypoint","children":null}]},{"value":"go.mod","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/max children)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- d
+		- x.go
+		- y.go
+		- z
+			- w.go
+	- c
+	- b
+		- v.go`)),
+				options: []gtree.Option{
+					gtree.WithMaxChildren(2),
+					gtree.WithSort(gtree.SortByName),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── v.go
+├── c
+└── … 5 more (2 dirs, 3 files)
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/max depth with file extensions)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- x.go
+		- y.go
+		- z
+			- w.go
+	- c`)),
+				options: []gtree.Option{
+					gtree.WithMaxDepth(1),
+					gtree.WithFileExtensions([]string{".go"}),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── … 4 more (1 dir, 3 files)
+└── c
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and max depth)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c
+			- d
+	- e`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithMaxDepth(2),
+					gtree.WithMaxChildren(1),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── c
+│       └── … 1 more (1 file)
+└── … 1 more (1 file)
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode markdown skips summary)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+	- c`)),
+				options: []gtree.Option{
+					gtree.WithMaxChildren(1),
+					gtree.WithEncodeMarkdown(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+- a
+	- b
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode mermaid with summary)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- x
+	- c
+	- d`)),
+				options: []gtree.Option{
+					gtree.WithMaxDepth(1),
+					gtree.WithMaxChildren(1),
+					gtree.WithEncodeMermaid(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+graph TD
+    n0["a"]
+    n0 --> n0_0["b"]
+    n0_0 -.-> n0_0_0(["… 1 more (1 file)"])
+    n0 -.-> n0_1(["… 2 more (2 files)"])
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode dot with summary)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+	- c`)),
+				options: []gtree.Option{
+					gtree.WithMaxChildren(1),
+					gtree.WithEncodeDOT(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+digraph gtree {
+    n0 [label="a"];
+    n0_0 [label="b"];
+    n0 -> n0_0;
+    n0_1 [label="… 1 more (1 file)", style=dashed];
+    n0 -> n0_1 [style=dashed];
+}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/encode html with summary)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b.go
+	- c.go`)),
+				options: []gtree.Option{
+					gtree.WithMaxChildren(1),
+					gtree.WithEncodeHTML(),
+					gtree.WithFileExtensions([]string{".go"}),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gtree</title>
+</head>
+<body>
+<div class="gtree">
+<details class="dir" open><summary>a</summary>
+  <div class="file">b.go</div>
+  <div class="summary">… 1 more (1 file)</div>
+</details>
+</div>
+</body>
+</html>
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/filter)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- api
+		- v1
+			- user.proto
+			- user.go
+		- v2
+			- README.md
+	- proto
+		- common.proto
+	- node_modules
+		- x.proto
+	- main.go`)),
+				options: []gtree.Option{
+					gtree.WithFilter(isProto),
+					gtree.WithExclude("node_modules"),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+├── api
+│   └── v1
+│       └── user.proto
+└── proto
+    └── common.proto
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/filter by path)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- api
+		- v1
+			- user.proto
+			- user.go
+		- v2
+			- README.md
+	- proto
+		- common.proto
+	- node_modules
+		- x.proto
+	- main.go`)),
+				options: []gtree.Option{
+					gtree.WithFilter(func(wn *gtree.WalkerNode) bool { return strings.HasPrefix(wn.Path(), "repo/api/") }),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+└── api
+    ├── v1
+    │   ├── user.proto
+    │   └── user.go
+    └── v2
+        └── README.md
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/exclude)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- api
+		- v1
+			- user.proto
+			- user.go
+		- v2
+			- README.md
+	- proto
+		- common.proto
+	- node_modules
+		- x.proto
+	- main.go`)),
+				options: []gtree.Option{
+					gtree.WithExclude("*.go", "node_modules", "v?"),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+├── api
+└── proto
+    └── common.proto
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and filter)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- api
+		- v1
+			- user.proto
+			- user.go
+		- v2
+			- README.md
+	- proto
+		- common.proto
+	- node_modules
+		- x.proto
+	- main.go`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithFilter(isProto),
+					gtree.WithMaxChildren(1),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+├── api
+│   └── v1
+│       └── user.proto
+└── … 4 more (2 dirs, 2 files)
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(malformed exclude pattern)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- api
+		- v1
+			- user.proto
+			- user.go
+		- v2
+			- README.md
+	- proto
+		- common.proto
+	- node_modules
+		- x.proto
+	- main.go`)),
+				options: []gtree.Option{
+					gtree.WithExclude("v[2"),
+				},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`syntax error in pattern: "v[2"`),
+			},
+		},
+		{
+			name: "case(succeeded/compact chains)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- src # sources
+		- main
+			- java
+				- com
+					- example
+						- App.java
+	- docs
+		- README.md
+	- pom.xml`)),
+				options: []gtree.Option{gtree.WithCompactChains()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+├── src/main/java/com/example  # sources
+│   └── App.java
+├── docs
+│   └── README.md
+└── pom.xml
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and compact chains with max depth)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- repo
+	- src # sources
+		- main
+			- java
+				- com
+					- example
+						- App.java
+	- docs
+		- README.md
+	- pom.xml`)),
+				options: []gtree.Option{gtree.WithMassive(context.Background()), gtree.WithCompactChains(), gtree.WithMaxDepth(1)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+repo
+├── src/main/java/com/example  # sources
+│   └── … 1 more (1 file)
+├── docs
+│   └── … 1 more (1 file)
+└── pom.xml
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/summary)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c.go
+	- d.md
+- e`)),
+				options: []gtree.Option{gtree.WithSummary()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── c.go
+└── d.md
+
+2 directories, 2 files
+e
+
+0 directories, 1 files
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and summary with truncation)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c.go
+	- d.md`)),
+				options: []gtree.Option{gtree.WithMassive(context.Background()), gtree.WithSummary(), gtree.WithMaxDepth(1), gtree.WithFileExtensions([]string{".go"})},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── … 1 more (1 file)
+└── d.md
+
+3 directories, 0 files
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/sort by name desc)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+	- d
+	- c`)),
+				options: []gtree.Option{
+					gtree.WithSort(gtree.SortByNameDesc),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── d
+├── c
+└── b
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/sort natural)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- v1.10.0
+	- file10
+	- v1.9.2
+	- file2
+	- file02
+	- v1.9.10`)),
+				options: []gtree.Option{
+					gtree.WithSort(gtree.SortNatural),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── file2
+├── file02
+├── file10
+├── v1.9.2
+├── v1.9.10
+└── v1.10.0
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/sort dirs first)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- main.go
+	- Makefile
+	- pkg
+		- b.go
+		- a.go
+	- cmd
+	- README.md`)),
+				options: []gtree.Option{
+					gtree.WithSort(gtree.SortDirsFirst, gtree.SortByName),
+					gtree.WithFileExtensions([]string{".go", ".md", "Makefile"}),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── cmd
+├── pkg
+│   ├── a.go
+│   └── b.go
+├── Makefile
+├── README.md
+└── main.go
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and json and sort)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- c
+	- b`)),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithEncodeJSON(),
+					gtree.WithSort(gtree.SortByName),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","children":[{"value":"b","children":null},{"value":"c","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/decode tree)",
+			in: in{
+				input: strings.NewReader(strings.TrimPrefix(`
+a
+├── b
+│   ├── c
+│   └── d
+│       └── e
+└── f
+    └── g
+h
+└── i
+
+5 directories, 4 files
+`, "\n")),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   ├── c
+│   └── d
+│       └── e
+└── f
+    └── g
+h
+└── i
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/decode tree with custom branch)",
+			in: in{
+				input: strings.NewReader(strings.TrimPrefix(`
+a
++-- b
+:   +-- c
+:   +-- d
+:       +-- e
++-- f
+    +-- g
+`, "\n")),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+					gtree.WithBranchFormatIntermedialNode("+--", ":   "),
+					gtree.WithBranchFormatLastNode("+--", "    "),
+					gtree.WithEncodeJSON(),
+				},
+			},
+			out: out{
+				output: `{"value":"a","children":[{"value":"b","children":[{"value":"c","children":null},{"value":"d","children":[{"value":"e","children":null}]}]},{"value":"f","children":[{"value":"g","children":null}]}]}` + "\n",
+				err:    nil,
+			},
+		},
+		{
+			name: "case(succeeded/decode tree with no-break space)",
+			in: in{
+				input: strings.NewReader("a\n├── b\n│\u00a0\u00a0 └── c\n└── d\n"),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── c
+└── d
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/decode tree surrounded by fence)",
+			in: in{
+				input: strings.NewReader("" +
+					"```text\n" +
+					"a\n" +
+					"├── b\n" +
+					"└── c\n" +
+					"```\n" +
+					"~~~\n" +
+					"d\n" +
+					"└── e\n" +
+					"~~~\n"),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+└── c
+d
+└── e
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/when massive root and decode tree surrounded by fence)",
+			in: in{
+				input: strings.NewReader("" +
+					"```\n" +
+					"a\n" +
+					"└── b\n" +
+					"    └── c\n" +
+					"```\n"),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+└── b
+    └── c
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(decode tree/empty text)",
+			in: in{
+				input: strings.NewReader("a\n├── b\n└──\n"),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: "",
+				err:    gtree.ExportErrEmptyText("└──", 3),
+			},
+		},
+		{
+			name: "case(decode tree/no branch to node)",
+			in: in{
+				input: strings.NewReader("a\n├── b\n│   c\n"),
+				options: []gtree.Option{
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: "",
+				err:    gtree.ExportErrIncorrectFormatAt("│   c", 3, 7),
+			},
+		},
+		{
+			// 複数Rootブロックを指定すべきだが、実装上、出力の順番が保証されないため1Rootで実施
+			name: "case(succeeded/when massive root and decode tree)",
+			in: in{
+				input: strings.NewReader(strings.TrimPrefix(`
+a
+└── b
+    ├── c
+    └── d
+`, "\n")),
+				options: []gtree.Option{
+					gtree.WithMassive(context.Background()),
+					gtree.WithDecodeTree(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+└── b
+    ├── c
+    └── d
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/code block)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace("" +
+					"- a\n" +
+					"\t- main.go\n" +
+					"\t\t```go\n" +
+					"\t\tpackage main\n" +
+					"\t\t```\n" +
+					"\t- b")),
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── main.go
+└── b
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/code block/encode markdown)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace("" +
+					"- a\n" +
+					"\t- main.go\n" +
+					"\t\t````go\n" +
+					"\t\tpackage main\n" +
+					"\n" +
+					"\t\t```\n" +
+					"\t\tx\n" +
+					"\t\t````\n" +
+					"\t- b")),
+				options: []gtree.Option{
+					gtree.WithEncodeMarkdown(),
+				},
+			},
+			out: out{
+				output: "" +
+					"- a\n" +
+					"\t- main.go\n" +
+					"\t\t````go\n" +
+					"\t\tpackage main\n" +
+					"\n" +
+					"\t\t```\n" +
+					"\t\tx\n" +
+					"\t\t````\n" +
+					"\t- b\n",
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/code block/dry run)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace("" +
+					"- a\n" +
+					"\t- main.go\n" +
+					"\t\t```go\n" +
+					"\t\tpackage main\n" +
+					"\t\t```\n" +
+					"\t- .keep\n" +
+					"\t\t~~~\n" +
+					"\t\t~~~")),
+				options: []gtree.Option{
+					gtree.WithDryRun(),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── main.go (13 bytes)
+└── .keep (0 bytes)
+
+1 directories, 2 files
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(code block not under node)",
+			in: in{
+				input: strings.NewReader("```\n```\n- a\n"),
+			},
+			out: out{
+				output: "",
+				err:    errors.New("1:1: code block must be indented under a node: \"```\""),
+			},
+		},
+		{
+			name: "case(succeeded/template data)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace("" +
+					"- {{.Service}}-api\n" +
+					"\t- cmd\n" +
+					"\t\t- {{.Service | printf \"%s-server\"}}\n" +
+					"\t- {{\"{{\"}}.Literal}}")),
+				options: []gtree.Option{
+					gtree.WithTemplateData(map[string]string{"Service": "billing"}),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+billing-api
+├── cmd
+│   └── billing-server
+└── {{.Literal}}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/template data/struct/massive)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- {{.Service}}-api
+	- {{.Owner}}`)),
+				options: []gtree.Option{
+					gtree.WithTemplateData(struct{ Service, Owner string }{"billing", "team"}),
+					gtree.WithMassive(context.Background()),
+				},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+billing-api
+└── team
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/without template data)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- {{.Service}}-api`)),
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{{.Service}}-api
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(missing template variable)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- {{.Service}}-api
+	- {{.Owner}}`)),
+				options: []gtree.Option{
+					gtree.WithTemplateData(map[string]any{"Service": "billing"}),
+				},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`2:6: missing template variable .Owner: "\t- {{.Owner}}"`),
+			},
+		},
+		{
+			name: "case(missing template variable/struct/massive)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- {{.Service}}-api
+	- {{.Owner}}`)),
+				options: []gtree.Option{
+					gtree.WithTemplateData(struct{ Service string }{"billing"}),
+					gtree.WithMassive(context.Background()),
+				},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`2:6: missing template variable .Owner: "\t- {{.Owner}}"`),
+			},
+		},
+		{
+			name: "case(mixed indentation)",
+			in: in{
+				input: strings.NewReader("- a\n\t- b\n\t - c"),
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`3:2: incorrect input format: mixed tab and space indentation: "\t - c"`),
+			},
+		},
+		{
+			name: "case(mixed indentation across roots)",
+			in: in{
+				input: strings.NewReader("- a\n\t- b\n- c\n  - d"),
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`4:3: incorrect input format: mixed tab and space indentation: "  - d"`),
+			},
+		},
+		{
+			name: "case(inconsistent indentation)",
+			in: in{
+				input: strings.NewReader("- a\n  - b\n     - c"),
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`3:6: incorrect input format: inconsistent indentation: "     - c"`),
+			},
+		},
+		{
+			name: "case(template data with line break)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- {{.Service}}`)),
+				options: []gtree.Option{
+					gtree.WithTemplateData(map[string]any{"Service": "billing\n\t- api"}),
+				},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`2:4: template rendered a line break: "\t- {{.Service}}"`),
+			},
+		},
+		{
+			name: "case(template syntax error)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- {{.Service`)),
+				options: []gtree.Option{
+					gtree.WithTemplateData(map[string]any{"Service": "billing"}),
+				},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`2:4: template: unclosed action: "\t- {{.Service"`),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			gotErr := gtree.Output(out, tt.in.input, tt.in.options...)
+			gotOutput := out.String()
+
+			if gotOutput != tt.out.output {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", gotOutput, tt.out.output)
+			}
+			if gotErr != nil {
+				if gotErr.Error() != tt.out.err.Error() {
+					t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
+				}
+			}
+
+			if file, ok := tt.in.input.(*os.File); ok {
+				file.Close()
+			}
+		})
+	}
+}
+
+func TestOutput_encodeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   in
+		out  out
+	}{
+		{
+			name: "case(tab spaces & multi root & output json)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- i
+		- u
+			- k
+			- kk
+		- t
+	- e
+		- o
+	- g
+- a
+	- i
+		- u
+			- k
+			- kk
+		- t
+	- e
+		- o
+	- g`)),
+				options: []gtree.Option{gtree.WithEncodeJSON()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(output json with comment)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a # root
+	- b # child`)),
+				options: []gtree.Option{gtree.WithEncodeJSON()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","comment":"root","children":[{"value":"b","comment":"child","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(output json with meta/same name node is merged)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a {owner: core}
+	- b {owner: team-a, size: 10}
+	- b {owner: team-b}`)),
+				options: []gtree.Option{gtree.WithEncodeJSON()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","meta":{"owner":"core"},"children":[{"value":"b","meta":{"owner":"team-b","size":"10"},"children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(output json with truncated)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c
+	- d
+	- e`)),
+				options: []gtree.Option{gtree.WithEncodeJSON(), gtree.WithMaxDepth(1), gtree.WithMaxChildren(1)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","truncated":2,"children":[{"value":"b","truncated":1,"children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(massive/output json with truncated)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+	- c`)),
+				options: []gtree.Option{gtree.WithEncodeJSON(), gtree.WithMassive(context.Background()), gtree.WithMaxChildren(1)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","truncated":1,"children":[{"value":"b","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(output json with filter)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- b
+		- c.proto
+	- d`)),
+				options: []gtree.Option{gtree.WithEncodeJSON(), gtree.WithFilter(isProto)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","children":[{"value":"b","children":[{"value":"c.proto","children":null}]}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(indent 2spaces & output json)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+  - i
+    - u
+      - k
+      - kk
+    - t
+  - e
+    - o
+  - g`)),
+				options: []gtree.Option{gtree.WithEncodeJSON()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(indent 4spaces & output json)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+    - i
+        - u
+            - k
+            - kk
+        - t
+    - e
+        - o
+    - g`)),
+				options: []gtree.Option{gtree.WithEncodeJSON()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+{"value":"a","children":[{"value":"i","children":[{"value":"u","children":[{"value":"k","children":null},{"value":"kk","children":null}]},{"value":"t","children":null}]},{"value":"e","children":[{"value":"o","children":null}]},{"value":"g","children":null}]}
+`, "\n"),
+				err: nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			gotErr := gtree.Output(out, tt.in.input, tt.in.options...)
+			gotOutput := out.String()
+
+			if gotOutput != tt.out.output {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", gotOutput, tt.out.output)
+			}
+			if gotErr != tt.out.err {
+				t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
+			}
+		})
+	}
+}
+
+func TestOutput_encodeTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   in
+		out  out
+	}{
+		{
+			name: "case(succeeded/tab spaces & multi root & output toml)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- i
+		- u
+			- k
+			- kk
+		- t
+	- e
+		- o
+	- g
+- a
+	- i
+		- u
+			- k
+			- kk
+		- t
+	- e
+		- o
+	- g`)),
+				options: []gtree.Option{gtree.WithEncodeTOML()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+[[root]]
+value = 'a'
+
+[[root.children]]
+value = 'i'
+
+[[root.children.children]]
+value = 'u'
+
+[[root.children.children.children]]
+value = 'k'
+children = []
+
+[[root.children.children.children]]
+value = 'kk'
+children = []
+
+[[root.children.children]]
+value = 't'
+children = []
+
+[[root.children]]
+value = 'e'
+
+[[root.children.children]]
+value = 'o'
+children = []
+
+[[root.children]]
+value = 'g'
+children = []
+[[root]]
+value = 'a'
+
+[[root.children]]
+value = 'i'
+
+[[root.children.children]]
+value = 'u'
+
+[[root.children.children.children]]
+value = 'k'
+children = []
+
+[[root.children.children.children]]
+value = 'kk'
+children = []
+
+[[root.children.children]]
+value = 't'
+children = []
+
+[[root.children]]
+value = 'e'
+
+[[root.children.children]]
+value = 'o'
+children = []
+
+[[root.children]]
+value = 'g'
+children = []
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/indent 2spaces & output toml)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+  - i
+    - u
+      - k
+      - kk
+    - t
+  - e
+    - o
+  - g`)),
+				options: []gtree.Option{gtree.WithEncodeTOML()},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+value = 'a'
+
+[[children]]
+value = 'i'
+
+[[children.children]]
+value = 'u'
+
+[[children.children.children]]
+value = 'k'
+children = []
+
 [[children.children.children]]
 value = 'kk'
 children = []
@@ -1118,6 +2761,50 @@ children = []
 	}
 }
 
+func TestOutput_encodeTOML_decode(t *testing.T) {
+	input := strings.TrimSpace(`
+- a
+	- b
+- x
+	- y`)
+	tree := []string{"a\n└── b\n", "x\n└── y\n"}
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+	}{
+		{
+			name: "case(multi root)",
+		},
+		{
+			name:    "case(massive/multi root)",
+			options: []gtree.Option{gtree.WithMassive(context.Background())},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			encoded := &bytes.Buffer{}
+			if err := gtree.Output(encoded, strings.NewReader(input), append(tt.options, gtree.WithEncodeTOML())...); err != nil {
+				t.Fatal(err)
+			}
+			decoded := &bytes.Buffer{}
+			if err := gtree.Output(decoded, encoded, append(tt.options, gtree.WithDecodeTOML())...); err != nil {
+				t.Fatal(err)
+			}
+
+			// Massiveモードでは、Rootの順番は保証されない
+			got := decoded.String()
+			if got != tree[0]+tree[1] && got != tree[1]+tree[0] {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", got, tree[0]+tree[1])
+			}
+		})
+	}
+}
+
 func TestOutput_encodeYAML(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1291,6 +2978,375 @@ children:
 }
 
 // TODO: config.go用にtest.goあってもいいんじゃないか
+func TestOutput_decode(t *testing.T) {
+	want := strings.TrimPrefix(`
+a
+├── b
+│   └── c
+└── d
+x
+└── y
+`, "\n")
+
+	tests := []struct {
+		name string
+		in   in
+		out  out
+	}{
+		{
+			name: "case(json stream)",
+			in: in{
+				input: strings.NewReader(`
+{"value":"a","children":[{"value":"b","children":[{"value":"c","children":null}]},{"value":"d","children":null}]}
+{"value":"x","children":[{"value":"y","children":null}]}
+`),
+				options: []gtree.Option{gtree.WithDecodeJSON()},
+			},
+			out: out{output: want},
+		},
+		{
+			name: "case(json array/same name is merged)",
+			in: in{
+				input: strings.NewReader(`[
+	{"value":"a","children":[{"value":"b"},{"value":"b","children":[{"value":"c"}]},{"value":"d"}]},
+	{"value":"x","children":[{"value":"y"}]}
+]`),
+				options: []gtree.Option{gtree.WithDecodeJSON()},
+			},
+			out: out{output: want},
+		},
+		{
+			name: "case(yaml documents)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+value: a
+children:
+    - value: b
+      children:
+        - value: c
+          children: []
+    - value: d
+      children: []
+---
+value: x
+children:
+    - value: "y"
+`)),
+				options: []gtree.Option{gtree.WithDecodeYAML()},
+			},
+			out: out{output: want},
+		},
+		{
+			name: "case(yaml sequence)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- value: a
+  children:
+    - value: b
+      children:
+        - value: c
+    - value: d
+- value: x
+  children:
+    - value: "y"
+`)),
+				options: []gtree.Option{gtree.WithDecodeYAML()},
+			},
+			out: out{output: want},
+		},
+		{
+			name: "case(toml roots in array of tables)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+[[root]]
+value = 'a'
+
+[[root.children]]
+value = 'b'
+
+[[root.children.children]]
+value = 'c'
+children = []
+
+[[root.children]]
+value = 'd'
+children = []
+
+[[root]]
+value = 'x'
+
+[[root.children]]
+value = 'y'
+children = []
+`)),
+				options: []gtree.Option{gtree.WithDecodeTOML()},
+			},
+			out: out{output: want},
+		},
+		{
+			name: "case(toml root output by WithEncodeTOML)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+value = 'x'
+
+[[children]]
+value = 'y'
+children = []
+`)),
+				options: []gtree.Option{gtree.WithDecodeTOML()},
+			},
+			out: out{output: strings.TrimPrefix(`
+x
+└── y
+`, "\n")},
+		},
+		{
+			name: "case(toml/roots without array of tables)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+value = 'a'
+children = []
+value = 'x'
+children = []
+`)),
+				options: []gtree.Option{gtree.WithDecodeTOML()},
+			},
+			out: out{err: errors.New("toml: key value is already defined")},
+		},
+		{
+			name: "case(toml/root and array of tables)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+value = 'a'
+
+[[root]]
+value = 'x'
+`)),
+				options: []gtree.Option{gtree.WithDecodeTOML()},
+			},
+			out: out{err: errors.New("toml input must have either a root or [[root]] tables, not both")},
+		},
+		{
+			name: "case(massive/toml roots in array of tables)",
+			in: in{
+				input:   strings.NewReader("[[root]]\nvalue = 'a'\n\n[[root]]\nvalue = 'x'\n"),
+				options: []gtree.Option{gtree.WithDecodeTOML(), gtree.WithMassive(context.Background())},
+			},
+			out: out{output: "a\nx\n"},
+		},
+		{
+			name: "case(yaml with comment)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+value: a
+comment: root
+children:
+    - value: b
+      comment: child`)),
+				options: []gtree.Option{gtree.WithDecodeYAML()},
+			},
+			out: out{output: strings.TrimPrefix(`
+a      # root
+└── b  # child
+`, "\n")},
+		},
+		{
+			name: "case(toml with meta)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+value = 'a'
+
+[meta]
+owner = 'core'
+
+[[children]]
+value = 'b'
+children = []
+
+[children.meta]
+size = 10
+`)),
+				options: []gtree.Option{gtree.WithDecodeTOML(), gtree.WithEncodeJSON()},
+			},
+			out: out{output: strings.TrimPrefix(`
+{"value":"a","meta":{"owner":"core"},"children":[{"value":"b","meta":{"size":10},"children":null}]}
+`, "\n")},
+		},
+		{
+			name: "case(massive/json)",
+			in: in{
+				input:   strings.NewReader(`{"value":"a","children":[{"value":"b","children":[{"value":"c"}]},{"value":"d"}]}`),
+				options: []gtree.Option{gtree.WithDecodeJSON(), gtree.WithMassive(context.Background())},
+			},
+			out: out{output: strings.TrimPrefix(`
+a
+├── b
+│   └── c
+└── d
+`, "\n")},
+		},
+		{
+			name: "case(json/empty value)",
+			in: in{
+				input:   strings.NewReader(`{"value":"a","children":[{"value":""}]}`),
+				options: []gtree.Option{gtree.WithDecodeJSON()},
+			},
+			out: out{err: errors.New("empty text: child of a")},
+		},
+		{
+			name: "case(massive/json/empty value)",
+			in: in{
+				input:   strings.NewReader(`{"value":""}`),
+				options: []gtree.Option{gtree.WithDecodeJSON(), gtree.WithMassive(context.Background())},
+			},
+			out: out{err: errors.New("empty text: root")},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			gotErr := gtree.Output(out, tt.in.input, tt.in.options...)
+			gotOutput := out.String()
+
+			if gotOutput != tt.out.output {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", gotOutput, tt.out.output)
+			}
+			if gotErr != nil || tt.out.err != nil {
+				if gotErr == nil || tt.out.err == nil || gotErr.Error() != tt.out.err.Error() {
+					t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
+				}
+			}
+		})
+	}
+}
+
+func TestOutput_template(t *testing.T) {
+	lines := template.Must(template.New("lines").Parse(
+		`{{range .Nodes}}{{.Row}}{{if .IsFile}} ({{.Path}}){{end}}{{"\n"}}{{end}}`,
+	))
+	document := template.Must(template.New("document").Parse(
+		`{{define "node"}}<li>{{.Name}}{{with .Meta}} {{.owner}}{{end}}{{if .Children}}<ul>{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}</li>{{end}}` +
+			`<ul>{{template "node" .}}</ul>{{"\n"}}`,
+	))
+	summary := template.Must(template.New("summary").Parse(
+		`{{range .Nodes}}{{if .IsSummary}}{{.Branch}} ({{.Name}}){{else}}{{.Row}}{{end}}{{"\n"}}{{end}}`,
+	))
+	failed := template.Must(template.New("failed").Parse(`{{.Unknown}}`))
+
+	input := strings.TrimSpace(`
+- a {owner: core}
+	- b
+		- c.go
+	- d
+- e`)
+
+	tests := []struct {
+		name string
+		in   in
+		out  out
+	}{
+		{
+			name: "case(succeeded/one line per node)",
+			in: in{
+				input:   strings.NewReader(input),
+				options: []gtree.Option{gtree.WithTemplate(lines)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── c.go (a/b/c.go)
+└── d (a/d)
+e (e)
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/whole document)",
+			in: in{
+				input:   strings.NewReader(input),
+				options: []gtree.Option{gtree.WithTemplate(document)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+<ul><li>a core<ul><li>b<ul><li>c.go</li></ul></li><li>d</li></ul></li></ul>
+<ul><li>e</li></ul>
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/massive)",
+			in: in{
+				input:   strings.NewReader(input),
+				options: []gtree.Option{gtree.WithMassive(context.Background()), gtree.WithTemplate(lines), gtree.WithFileExtensions([]string{".go"})},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── c.go (a/b/c.go)
+└── d
+e
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/summary)",
+			in: in{
+				input:   strings.NewReader(input),
+				options: []gtree.Option{gtree.WithTemplate(summary), gtree.WithMaxChildren(1)},
+			},
+			out: out{
+				output: strings.TrimPrefix(`
+a
+├── b
+│   └── c.go
+└── (… 1 more (1 file))
+e
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(failed to execute)",
+			in: in{
+				input:   strings.NewReader(input),
+				options: []gtree.Option{gtree.WithTemplate(failed)},
+			},
+			out: out{
+				output: "",
+				err:    errors.New(`template: failed:1:2: executing "failed" at <.Unknown>: can't evaluate field Unknown in type *gtree.TemplateNode`),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			gotErr := gtree.Output(buf, tt.in.input, tt.in.options...)
+			if gotErr != nil || tt.out.err != nil {
+				if gotErr == nil || tt.out.err == nil || gotErr.Error() != tt.out.err.Error() {
+					t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.out.err)
+				}
+				return
+			}
+			if got := buf.String(); got != tt.out.output {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", got, tt.out.output)
+			}
+		})
+	}
+}
+
 func TestOutput_nilctx(t *testing.T) {
 	w := io.Discard
 	r := strings.NewReader(tu.SingleRoot)
@@ -1308,3 +3364,47 @@ func TestOutput_nilopt(t *testing.T) {
 		t.Errorf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, nil)
 	}
 }
+
+func TestOutput_truncation_count(t *testing.T) {
+	input := &strings.Builder{}
+	fmt.Fprintln(input, "- root")
+	for i := 0; i < 1205; i++ {
+		fmt.Fprintf(input, "\t- %04d\n", i)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gtree.Output(buf, strings.NewReader(input.String()), gtree.WithMaxChildren(1)); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+root
+├── 0000
+└── … 1,204 more (1,204 files)
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
+
+func isProto(wn *gtree.WalkerNode) bool {
+	return strings.HasSuffix(wn.Name(), ".proto")
+}
+
+func TestOutput_highlight(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	buf := &bytes.Buffer{}
+	input := strings.NewReader(strings.TrimSpace(`
+- repo
+	- a.proto
+	- b.go`))
+	if err := gtree.Output(buf, input, gtree.WithFilter(isProto), gtree.WithHighlight()); err != nil {
+		t.Fatal(err)
+	}
+	want := "repo\n└── \x1b[1;93ma.proto\x1b[22;0m\n"
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%q\nwant: \n%q", got, want)
+	}
+}
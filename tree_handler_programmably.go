@@ -5,10 +5,9 @@ package gtree
 import (
 	"errors"
 	"io"
-)
-
-var (
-	idxCounter = newCounter()
+	"path"
+	"slices"
+	"strings"
 )
 
 // OutputProgrammably outputs tree to w.
@@ -18,9 +17,13 @@ func OutputProgrammably(w io.Writer, root *Node, options ...Option) error {
 		return err
 	}
 
-	idxCounter.reset()
-
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	if cfg.reshapesTree() {
+		root = root.Clone() // 利用者のツリーの形を変えないように、複製したツリーを扱う
+	}
 	return initializeTree(cfg).outputProgrammably(w, root, cfg)
 }
 
@@ -31,12 +34,38 @@ func MkdirProgrammably(root *Node, options ...Option) error {
 		return err
 	}
 
-	idxCounter.reset()
-
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	cfg.keepTreeShape()
+	if cfg.reshapesTree() {
+		root = root.Clone() // WithSort で利用者のツリーを並び替えないように、複製したツリーを扱う
+	}
 	return initializeTree(cfg).mkdirProgrammably(root, cfg)
 }
 
+// MkdirProgrammablyWithResult makes directories like MkdirProgrammably, and returns the result of each path handled.
+// The result is returned even if an error is returned, with the paths handled until then.
+func MkdirProgrammablyWithResult(root *Node, options ...Option) (MkdirResult, error) {
+	result := MkdirResult{Entries: []MkdirEntry{}}
+	if err := validateTreeRoot(root); err != nil {
+		return result, err
+	}
+
+	cfg, err := newConfig(options)
+	if err != nil {
+		return result, err
+	}
+	cfg.keepTreeShape()
+	if cfg.reshapesTree() {
+		root = root.Clone() // WithSort で利用者のツリーを並び替えないように、複製したツリーを扱う
+	}
+	cfg.mkdirResult = &result
+	err = initializeTree(cfg).mkdirProgrammably(root, cfg)
+	return result, err
+}
+
 // VerifyProgrammably verifies directory.
 // This function requires node generated by NewRoot function.
 func VerifyProgrammably(root *Node, options ...Option) error {
@@ -44,9 +73,14 @@ func VerifyProgrammably(root *Node, options ...Option) error {
 		return err
 	}
 
-	idxCounter.reset()
-
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	cfg.keepTreeShape()
+	if cfg.reshapesTree() {
+		root = root.Clone() // WithSort で利用者のツリーを並び替えないように、複製したツリーを扱う
+	}
 	return initializeTree(cfg).verifyProgrammably(root, cfg)
 }
 
@@ -57,15 +91,39 @@ func WalkProgrammably(root *Node, callback func(*WalkerNode) error, options ...O
 		return err
 	}
 
-	idxCounter.reset()
-
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
+	if cfg.reshapesTree() {
+		root = root.Clone() // 利用者のツリーの形を変えないように、複製したツリーを扱う
+	}
 	return initializeTree(cfg).walkProgrammably(root, callback, cfg)
 }
 
 // NewRoot creates a starting node for building tree.
+// Each tree has its own state, so independent trees can be built and passed to the functions concurrently.
+// A single tree must not be modified or passed to the functions from multiple goroutines at the same time.
 func NewRoot(text string) *Node {
-	return newNode(text, rootHierarchyNum, idxCounter.next())
+	idxCounter := newCounter()
+	root := newNode(text, rootHierarchyNum, idxCounter.next())
+	root.idxCounter = idxCounter
+	return root
+}
+
+func (parent *Node) newChild(text string) *Node {
+	child := newNode(text, parent.hierarchy+1, parent.nextChildIndex())
+	child.idxCounter = parent.idxCounter
+	child.setParent(parent)
+	return child
+}
+
+// 兄弟ノード間で index が重複しないように、子ノードの index は常に親ノードの採番器から振る
+func (parent *Node) nextChildIndex() uint {
+	if parent.idxCounter == nil {
+		parent.idxCounter = newCounter()
+	}
+	return parent.idxCounter.next()
 }
 
 // Add adds a node and returns an instance of it.
@@ -75,17 +133,175 @@ func (parent *Node) Add(text string) *Node {
 		return child
 	}
 
-	current := newNode(text, parent.hierarchy+1, idxCounter.next())
-	current.setParent(parent)
+	current := parent.newChild(text)
 	parent.addChild(current)
 	return current
 }
 
+// Name returns the text of the node.
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Comment returns the annotation of the node.
+func (n *Node) Comment() string {
+	return n.comment
+}
+
+// Meta returns the metadata of the node set with the key. It returns nil if it has not been set.
+func (n *Node) Meta(key string) any {
+	return n.meta[key]
+}
+
+// Parent returns the parent node. It returns nil if the node is a root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Children returns the child nodes in order.
+// The returned slice is a copy, so modifying it does not affect the tree.
+func (n *Node) Children() []*Node {
+	children := make([]*Node, len(n.children))
+	copy(children, n.children)
+	return children
+}
+
+// Path returns the path from the root node to this node.
+// The separator is / in any OS execution environment.
+func (n *Node) Path() string {
+	names := []string{}
+	for current := n; current != nil; current = current.parent {
+		names = append([]string{current.name}, names...)
+	}
+	return path.Join(names...)
+}
+
+// Depth returns the number of ancestors of the node. The root node is 0.
+func (n *Node) Depth() uint {
+	return n.hierarchy - rootHierarchyNum
+}
+
+// Remove removes the node and its descendants from the parent.
+// The removed node becomes a root, so it can be passed to OutputProgrammably etc. or moved with MoveTo.
+// Nothing is done for a root node.
+func (n *Node) Remove() {
+	if n.parent == nil {
+		return
+	}
+
+	n.parent.children = slices.DeleteFunc(n.parent.children, func(child *Node) bool {
+		return child == n
+	})
+	n.parent = nil
+	n.setHierarchy(rootHierarchyNum)
+}
+
+// Rename changes the text of the node.
+// ErrSameNameSibling is returned if a sibling with the text already exists.
+func (n *Node) Rename(text string) error {
+	if n.parent != nil {
+		if sibling := n.parent.findChildByText(text); sibling != nil && sibling != n {
+			return ErrSameNameSibling
+		}
+	}
+	n.name = text
+	return nil
+}
+
+// SetComment sets the annotation of the node. It is output in a column aligned to the right of the tree,
+// and as the "comment" field of JSON, YAML and TOML. It does not affect the paths made by MkdirProgrammably.
+// Consecutive whitespace including line breaks in comment is collapsed into a single space. An empty comment removes the annotation.
+func (n *Node) SetComment(comment string) {
+	n.comment = strings.Join(strings.Fields(comment), " ")
+}
+
+// SetMeta sets the metadata of the node with the key. Setting nil removes the key.
+// The metadata is output as the "meta" map of JSON, YAML and TOML, and as "{key: value}" of Markdown.
+// It can be read with WalkerNode.Meta. It does not affect the paths made by MkdirProgrammably.
+func (n *Node) SetMeta(key string, v any) {
+	n.setMeta(key, v)
+}
+
+// MoveTo moves the node and its descendants to the end of the children of parent.
+// If parent is already the parent of the node, the node moves to the end of its siblings.
+// ErrNilNode is returned if parent is nil, ErrCyclicMove is returned if parent is the node itself or its descendant,
+// and ErrSameNameSibling is returned if parent already has another child with the same text.
+func (n *Node) MoveTo(parent *Node) error {
+	if parent == nil {
+		return ErrNilNode
+	}
+	for ancestor := parent; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor == n {
+			return ErrCyclicMove
+		}
+	}
+	if sibling := parent.findChildByText(n.name); sibling != nil && sibling != n {
+		return ErrSameNameSibling
+	}
+
+	n.Remove()
+	n.index = parent.nextChildIndex() // 移動先の兄弟と index が重複しないように振り直す
+	n.setParent(parent)
+	parent.addChild(n)
+	n.setHierarchy(parent.hierarchy + 1)
+	return nil
+}
+
+// InsertAt adds a node at position i of the children and returns an instance of it.
+// i is clamped to the range of the children, so a negative number inserts at the beginning and a too large number at the end.
+// If a node with the same text already exists in the same hierarchy of the tree, that node will be returned without being moved.
+func (parent *Node) InsertAt(i int, text string) *Node {
+	if child := parent.findChildByText(text); child != nil {
+		return child
+	}
+
+	i = max(0, min(i, len(parent.children)))
+	current := parent.newChild(text)
+	parent.children = slices.Insert(parent.children, i, current)
+	return current
+}
+
+// Clone returns a deep copy of the node and its descendants.
+// The copy is a root that does not share any node with the original tree.
+func (n *Node) Clone() *Node {
+	c := NewRoot(n.name)
+	n.copyAttributes(c)
+	n.cloneChildren(c)
+	return c
+}
+
+func (n *Node) cloneChildren(c *Node) {
+	for _, child := range n.children {
+		cc := c.newChild(child.name)
+		child.copyAttributes(cc)
+		c.addChild(cc)
+		child.cloneChildren(cc)
+	}
+}
+
+// 名前と木構造以外の、入力で指定された属性を複製する
+func (n *Node) copyAttributes(c *Node) {
+	c.comment = n.comment
+	c.mergeMeta(n.meta)
+	c.mergeContent(slices.Clone(n.content), n.contentInfo)
+}
+
+func (n *Node) setHierarchy(hierarchy uint) {
+	n.hierarchy = hierarchy
+	for _, child := range n.children {
+		child.setHierarchy(hierarchy + 1)
+	}
+}
+
 var (
 	// ErrNilNode is returned if the argument *gtree.Node of OutputProgrammably / MkdirProgrammably / VerifyProgrammably function is nill.
 	ErrNilNode = errors.New("nil node")
 	// ErrNotRoot is returned if the argument *gtree.Node of OutputProgrammably / MkdirProgrammably / VerifyProgrammably function is not root of the tree.
 	ErrNotRoot = errors.New("not root node")
+	// ErrSameNameSibling is returned if Rename / MoveTo method of *gtree.Node makes siblings with the same text.
+	ErrSameNameSibling = errors.New("node with the same name already exists")
+	// ErrCyclicMove is returned if MoveTo method of *gtree.Node moves the node under itself or its descendant.
+	ErrCyclicMove = errors.New("cannot move node under itself or its descendant")
 )
 
 func validateTreeRoot(root *Node) error {
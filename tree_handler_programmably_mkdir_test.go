@@ -3,6 +3,7 @@ package gtree_test
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/ddddddO/gtree"
@@ -90,3 +91,66 @@ func TestMkdirProgrammably(t *testing.T) {
 		})
 	}
 }
+
+func TestMkdirProgrammablyWithResult(t *testing.T) {
+	t.Parallel()
+
+	root := gtree.NewRoot("root")
+	root.Add("a").Add("b.go")
+	root.Add("c")
+
+	got, err := gtree.MkdirProgrammablyWithResult(root,
+		gtree.WithTargetDir(t.TempDir()),
+		gtree.WithFileExtensions([]string{".go"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []gtree.MkdirEntry{
+		{Path: "root", Status: gtree.MkdirCreatedDir},
+		{Path: "root/a", Status: gtree.MkdirCreatedDir},
+		{Path: "root/a/b.go", Status: gtree.MkdirCreatedFile, IsFile: true},
+		{Path: "root/c", Status: gtree.MkdirCreatedDir},
+	}
+	if !reflect.DeepEqual(got.Entries, want) {
+		t.Errorf("\ngot: \n%+v\nwant: \n%+v", got.Entries, want)
+	}
+
+	got, err = gtree.MkdirProgrammablyWithResult(tu.PrepareNotRoot())
+	if err != gtree.ErrNotRoot || len(got.Entries) != 0 {
+		t.Errorf("\ngot: \n%+v, %v\nwant: \n[], %v", got.Entries, err, gtree.ErrNotRoot)
+	}
+}
+
+func TestMkdirProgrammably_sort(t *testing.T) {
+	t.Parallel()
+
+	root := gtree.NewRoot("root")
+	root.Add("b")
+	root.Add("a")
+	dir := t.TempDir()
+
+	got, err := gtree.MkdirProgrammablyWithResult(root, gtree.WithTargetDir(dir), gtree.WithSort(gtree.SortByName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []gtree.MkdirEntry{
+		{Path: "root", Status: gtree.MkdirCreatedDir},
+		{Path: "root/a", Status: gtree.MkdirCreatedDir},
+		{Path: "root/b", Status: gtree.MkdirCreatedDir},
+	}
+	if !reflect.DeepEqual(got.Entries, want) {
+		t.Errorf("\ngot: \n%+v\nwant: \n%+v", got.Entries, want)
+	}
+	if err := gtree.MkdirProgrammably(root, gtree.WithTargetDir(dir), gtree.WithMkdirMode(gtree.MkdirMerge), gtree.WithSort(gtree.SortByName)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gtree.VerifyProgrammably(root, gtree.WithTargetDir(dir), gtree.WithSort(gtree.SortByName)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 利用者のツリーは並び替えない
+	if got := root.Children()[0].Name(); got != "b" {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, "b")
+	}
+}
@@ -0,0 +1,355 @@
+package gtree_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ddddddO/gtree"
+)
+
+func TestNode_accessors(t *testing.T) {
+	root := gtree.NewRoot("root")
+	child1 := root.Add("child 1")
+	child2 := child1.Add("child 2")
+	child3 := root.Add("child 3")
+
+	if got := root.Parent(); got != nil {
+		t.Errorf("\ngot: \n%v\nwant: \nnil", got)
+	}
+	if got := child2.Parent(); got != child1 {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got.Name(), child1.Name())
+	}
+	if got := root.Children(); len(got) != 2 || got[0] != child1 || got[1] != child3 {
+		t.Errorf("\ngot: \n%v\nwant: \n[child 1 child 3]", got)
+	}
+	if got, want := child2.Path(), "root/child 1/child 2"; got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+	if got, want := root.Depth(), uint(0); got != want {
+		t.Errorf("\ngot: \n%d\nwant: \n%d", got, want)
+	}
+	if got, want := child2.Depth(), uint(2); got != want {
+		t.Errorf("\ngot: \n%d\nwant: \n%d", got, want)
+	}
+
+	children := root.Children()
+	children[0] = nil
+	if root.Children()[0] != child1 {
+		t.Error("modifying the returned slice must not affect the tree")
+	}
+}
+
+func TestNode_edit(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func() (*gtree.Node, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "case(remove)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				root.Add("child 1").Add("child 2")
+				root.Add("child 3").Add("child 4")
+				root.Children()[1].Remove()
+				return root, nil
+			},
+			want: strings.TrimPrefix(`
+root
+└── child 1
+    └── child 2
+`, "\n"),
+		},
+		{
+			name: "case(removed node becomes root)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				child1 := root.Add("child 1")
+				child1.Add("child 2").Add("child 3")
+				child1.Remove()
+				return child1, nil
+			},
+			want: strings.TrimPrefix(`
+child 1
+└── child 2
+    └── child 3
+`, "\n"),
+		},
+		{
+			name: "case(rename)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				root.Add("child 1").Add("child 2")
+				return root, root.Children()[0].Rename("renamed")
+			},
+			want: strings.TrimPrefix(`
+root
+└── renamed
+    └── child 2
+`, "\n"),
+		},
+		{
+			name: "case(rename to same name sibling)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				root.Add("child 1")
+				child2 := root.Add("child 2")
+				return root, child2.Rename("child 1")
+			},
+			wantErr: gtree.ErrSameNameSibling,
+		},
+		{
+			name: "case(move to)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				child1 := root.Add("child 1")
+				child2 := child1.Add("child 2")
+				child2.Add("child 3")
+				child4 := root.Add("child 4")
+				return root, child2.MoveTo(child4)
+			},
+			want: strings.TrimPrefix(`
+root
+├── child 1
+└── child 4
+    └── child 2
+        └── child 3
+`, "\n"),
+		},
+		{
+			name: "case(move to another tree)",
+			edit: func() (*gtree.Node, error) {
+				root1 := gtree.NewRoot("root1")
+				root1.Add("child 1")
+				root2 := gtree.NewRoot("root2")
+				root2.Add("child 2").Add("child 3")
+				return root1, root2.MoveTo(root1.Children()[0])
+			},
+			want: strings.TrimPrefix(`
+root1
+└── child 1
+    └── root2
+        └── child 2
+            └── child 3
+`, "\n"),
+		},
+		{
+			name: "case(move to descendant)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				child1 := root.Add("child 1")
+				child2 := child1.Add("child 2")
+				return root, child1.MoveTo(child2)
+			},
+			wantErr: gtree.ErrCyclicMove,
+		},
+		{
+			name: "case(move to parent with same name child)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				root.Add("child 1")
+				dup := root.Add("child 2").Add("child 1")
+				return root, dup.MoveTo(root)
+			},
+			wantErr: gtree.ErrSameNameSibling,
+		},
+		{
+			name: "case(move to current parent)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				child1 := root.Add("child 1")
+				child1.Add("child 2")
+				root.Add("child 3")
+				return root, child1.MoveTo(root)
+			},
+			want: strings.TrimPrefix(`
+root
+├── child 3
+└── child 1
+    └── child 2
+`, "\n"),
+		},
+		{
+			name: "case(move to nil)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				return root, root.Add("child 1").MoveTo(nil)
+			},
+			wantErr: gtree.ErrNilNode,
+		},
+		{
+			name: "case(insert at)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				root.Add("child 2")
+				root.Add("child 4")
+				root.InsertAt(1, "child 3")
+				root.InsertAt(-1, "child 1")
+				root.InsertAt(100, "child 5")
+				root.InsertAt(0, "child 4")
+				return root, nil
+			},
+			want: strings.TrimPrefix(`
+root
+├── child 1
+├── child 2
+├── child 3
+├── child 4
+└── child 5
+`, "\n"),
+		},
+		{
+			name: "case(clone)",
+			edit: func() (*gtree.Node, error) {
+				root := gtree.NewRoot("root")
+				child1 := root.Add("child 1")
+				child1.Add("child 2")
+
+				cloned := child1.Clone()
+				cloned.Add("child 3")
+				if err := cloned.MoveTo(root.Add("child 4")); err != nil {
+					return nil, err
+				}
+				return root, child1.Rename("original")
+			},
+			want: strings.TrimPrefix(`
+root
+├── original
+│   └── child 2
+└── child 4
+    └── child 1
+        ├── child 2
+        └── child 3
+`, "\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			root, gotErr := tt.edit()
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("\ngotErr: \n%v\nwantErr: \n%v", gotErr, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			buf := &bytes.Buffer{}
+			if err := gtree.OutputProgrammably(buf, root); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode_SetComment(t *testing.T) {
+	root := gtree.NewRoot("root")
+	root.SetComment("the root")
+	child := root.Add("main.go")
+	child.SetComment("entry\npoint")
+	root.Add("go.mod")
+
+	if got, want := child.Comment(), "entry point"; got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+		want    string
+	}{
+		{
+			name: "case(default)",
+			want: strings.TrimPrefix(`
+root         # the root
+├── main.go  # entry point
+└── go.mod
+`, "\n"),
+		},
+		{
+			name:    "case(yaml)",
+			options: []gtree.Option{gtree.WithEncodeYAML()},
+			want: strings.TrimPrefix(`
+value: root
+comment: the root
+children:
+    - value: main.go
+      comment: entry point
+      children: []
+    - value: go.mod
+      children: []
+`, "\n"),
+		},
+		{
+			name:    "case(clone)",
+			options: []gtree.Option{gtree.WithEncodeMarkdown()},
+			want: strings.TrimPrefix(`
+- root # the root
+	- main.go # entry point
+	- go.mod
+`, "\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := root
+			if tt.name == "case(clone)" {
+				target = root.Clone()
+			}
+
+			buf := &bytes.Buffer{}
+			if err := gtree.OutputProgrammably(buf, target, tt.options...); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("\ngot: \n%s\nwant: \n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode_SetMeta(t *testing.T) {
+	root := gtree.NewRoot("root")
+	child := root.Add("api.go")
+	child.SetMeta("owner", "team-a")
+	child.SetMeta("size", 10)
+	child.SetMeta("tmp", true)
+	child.SetMeta("tmp", nil)
+
+	if got, want := child.Meta("size"), any(10); got != want {
+		t.Errorf("\ngot: \n%v\nwant: \n%v", got, want)
+	}
+	if got := child.Meta("tmp"); got != nil {
+		t.Errorf("\ngot: \n%v\nwant: \nnil", got)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root.Clone(), gtree.WithEncodeJSON()); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"value":"root","children":[{"value":"api.go","meta":{"owner":"team-a","size":10},"children":null}]}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+
+	got := []string{}
+	if err := gtree.WalkProgrammably(root, func(wn *gtree.WalkerNode) error {
+		got = append(got, fmt.Sprintf("%s=%v", wn.Name(), wn.Meta("owner")))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "root=<nil> api.go=team-a"; strings.Join(got, " ") != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", strings.Join(got, " "), want)
+	}
+}
@@ -108,6 +108,26 @@ root1
 :           +-- child 6
 :               +-- child 7
 +-- child 8
+`, "\n"),
+		},
+		{
+			name: "case(succeeded / sort)",
+			root: tu.PrepareMultiNode(),
+			options: []gtree.Option{
+				gtree.WithSort(func(a, b *gtree.WalkerNode) int {
+					return strings.Compare(b.Name(), a.Name())
+				}),
+			},
+			want: strings.TrimPrefix(`
+root1
+├── child 8
+└── child 1
+    └── child 2
+        ├── child 4
+        │   ├── child 6
+        │   │   └── child 7
+        │   └── child 5
+        └── child 3
 `, "\n"),
 		},
 		{
@@ -199,3 +219,174 @@ children = []
 		})
 	}
 }
+
+func TestOutputProgrammably_truncation(t *testing.T) {
+	root := gtree.NewRoot("root")
+	root.Add("a").Add("b").Add("c")
+	root.Add("d")
+
+	for _, options := range [][]gtree.Option{
+		{gtree.WithMaxDepth(1), gtree.WithMaxChildren(1)},
+		{gtree.WithMaxDepth(1), gtree.WithMaxChildren(1), gtree.WithMassive(context.Background())},
+	} {
+		buf := &bytes.Buffer{}
+		if err := gtree.OutputProgrammably(buf, root, options...); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.TrimPrefix(`
+root
+├── a
+│   └── … 2 more (1 dir, 1 file)
+└── … 1 more (1 file)
+`, "\n")
+		if got := buf.String(); got != want {
+			t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+		}
+	}
+
+	// 利用者のツリーは切り詰めない
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+root
+├── a
+│   └── b
+│       └── c
+└── d
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
+
+func TestOutputProgrammably_filter(t *testing.T) {
+	root := gtree.NewRoot("root")
+	root.Add("a").Add("b.proto")
+	root.Add("c")
+
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root, gtree.WithFilter(func(wn *gtree.WalkerNode) bool {
+		return strings.HasSuffix(wn.Name(), ".proto")
+	})); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+root
+└── a
+    └── b.proto
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+
+	// 利用者のツリーからは取り除かない
+	if got := len(root.Children()); got != 2 {
+		t.Errorf("\ngot: \n%d\nwant: \n%d", got, 2)
+	}
+}
+
+func TestOutputProgrammably_sort(t *testing.T) {
+	root := gtree.NewRoot("root")
+	root.Add("b")
+	root.Add("a")
+
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root, gtree.WithSort(gtree.SortByName)); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+root
+├── a
+└── b
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+
+	// 利用者のツリーは並び替えない
+	if got := root.Children()[0].Name(); got != "b" {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, "b")
+	}
+}
+
+func TestOutputProgrammably_markdownRoundTrip(t *testing.T) {
+	root := gtree.NewRoot("n {k: v}")
+	root.SetMeta("owner", "core")
+	child := root.Add("a -- b")
+	child.SetMeta("note", "x # y")
+	child.SetMeta("range", "1 -- 2")
+	child.SetComment("c # d")
+	root.Add(`e \{f}`).SetMeta("url", "https://example.com/#top")
+
+	markdown := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(markdown, root, gtree.WithEncodeMarkdown()); err != nil {
+		t.Fatal(err)
+	}
+	wantMarkdown := strings.TrimPrefix(`
+- n \{k: v} {owner: core}
+	- a \-- b {note: "x # y", range: "1 -- 2"} # c # d
+	- e \\{f} {url: "https://example.com/#top"}
+`, "\n")
+	if got := markdown.String(); got != wantMarkdown {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, wantMarkdown)
+	}
+
+	want := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(want, root, gtree.WithEncodeJSON()); err != nil {
+		t.Fatal(err)
+	}
+	got := &bytes.Buffer{}
+	if err := gtree.Output(got, markdown, gtree.WithEncodeJSON()); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got.String(), want.String())
+	}
+}
+
+func TestOutputProgrammably_compactChains(t *testing.T) {
+	root := gtree.NewRoot("root")
+	root.Add("a").Add("b").Add("c.go")
+
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root, gtree.WithCompactChains()); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+root
+└── a/b
+    └── c.go
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+
+	// 利用者のツリーの形は変えない
+	if got := root.Children()[0].Name(); got != "a" {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, "a")
+	}
+}
+
+func TestOutputProgrammably_summary(t *testing.T) {
+	root := gtree.NewRoot("root")
+	root.Add("a").Add("b.go")
+	root.Add("c")
+
+	buf := &bytes.Buffer{}
+	if err := gtree.OutputProgrammably(buf, root, gtree.WithSummary(), gtree.WithFileExtensions([]string{".go"})); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+root
+├── a
+│   └── b.go
+└── c
+
+3 directories, 1 files
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
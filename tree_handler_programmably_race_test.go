@@ -0,0 +1,131 @@
+package gtree_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ddddddO/gtree"
+)
+
+const (
+	concurrentTreeNum  = 50
+	concurrentChildNum = 20
+)
+
+// 各ツリーの構築途中で他のツリーを出力しても、互いの index に影響しないこと
+func TestProgrammably_concurrent_independent_trees(t *testing.T) {
+	want := func(name string) string {
+		b := &strings.Builder{}
+		fmt.Fprintln(b, name)
+		for i := 0; i < concurrentChildNum; i++ {
+			if i == concurrentChildNum-1 {
+				fmt.Fprintf(b, "└── child %d\n", i)
+				fmt.Fprintf(b, "    └── grandchild %d\n", i)
+				break
+			}
+			fmt.Fprintf(b, "├── child %d\n", i)
+			fmt.Fprintf(b, "│   └── grandchild %d\n", i)
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name    string
+		options []gtree.Option
+	}{
+		{
+			name: "case(simple)",
+		},
+		{
+			name:    "case(massive)",
+			options: []gtree.Option{gtree.WithMassive(context.Background())},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			wg := &sync.WaitGroup{}
+			errc := make(chan error, concurrentTreeNum)
+			for n := 0; n < concurrentTreeNum; n++ {
+				wg.Add(1)
+				go func(n int) {
+					defer wg.Done()
+
+					name := fmt.Sprintf("root %d", n)
+					root := gtree.NewRoot(name)
+					for i := 0; i < concurrentChildNum; i++ {
+						root.Add(fmt.Sprintf("child %d", i)).Add(fmt.Sprintf("grandchild %d", i))
+						if err := gtree.OutputProgrammably(&bytes.Buffer{}, root, tt.options...); err != nil {
+							errc <- err
+							return
+						}
+					}
+
+					buf := &bytes.Buffer{}
+					if err := gtree.OutputProgrammably(buf, root, tt.options...); err != nil {
+						errc <- err
+						return
+					}
+					if got := buf.String(); got != want(name) {
+						errc <- fmt.Errorf("\ngot: \n%s\nwant: \n%s", got, want(name))
+					}
+				}(n)
+			}
+			wg.Wait()
+			close(errc)
+
+			for err := range errc {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestProgrammably_concurrent_walk_and_edit(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	errc := make(chan error, concurrentTreeNum)
+	for n := 0; n < concurrentTreeNum; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			root := gtree.NewRoot(fmt.Sprintf("root %d", n))
+			for i := 0; i < concurrentChildNum; i++ {
+				root.InsertAt(0, fmt.Sprintf("child %d", i))
+			}
+			root.Children()[0].Remove()
+			if err := root.Children()[0].MoveTo(root.Children()[1]); err != nil {
+				errc <- err
+				return
+			}
+
+			lasts := 0
+			if err := gtree.WalkProgrammably(root, func(wn *gtree.WalkerNode) error {
+				if strings.HasPrefix(wn.Branch(), "└──") || strings.HasSuffix(wn.Branch(), "└──") {
+					lasts++
+				}
+				return nil
+			}); err != nil {
+				errc <- err
+				return
+			}
+			// root 直下の最後のノードと、移動したノードの2つだけが └── になる
+			if lasts != 2 {
+				errc <- fmt.Errorf("root %d: got %d last nodes, want 2", n, lasts)
+			}
+		}(n)
+	}
+	wg.Wait()
+	close(errc)
+
+	for err := range errc {
+		t.Error(err)
+	}
+}
@@ -97,6 +97,22 @@ func TestVerify(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "case(succeeded/decode tree/strict mode/specify target dir)",
+			in: in{
+				input: strings.NewReader(strings.TrimPrefix(`
+like_cli
+├── adapter
+│   ├── executor.go
+│   └── indentation.go
+└── main.go
+`, "\n")),
+				options: []gtree.Option{gtree.WithDecodeTree(), gtree.WithStrictVerify(), gtree.WithTargetDir("example")},
+			},
+			out: out{
+				err: nil,
+			},
+		},
 		{
 			name: "case(error/specify target dir/Required paths does not exist)",
 			in: in{
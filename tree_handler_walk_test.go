@@ -2,6 +2,7 @@ package gtree_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -64,6 +65,54 @@ a
 :       +-- k
 +-- kk
     +-- t
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/sort)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- kk
+		- t
+	- i
+		- u
+			- k`)),
+				options: []gtree.Option{gtree.WithSort(gtree.SortByName)},
+			},
+			out: out{
+				output: strings.TrimLeft(`
+a
+├── i
+│   └── u
+│       └── k
+└── kk
+    └── t
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/massive and sort)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- kk
+		- t
+	- i
+		- u
+			- k`)),
+				options: []gtree.Option{gtree.WithMassive(context.Background()), gtree.WithSort(gtree.SortByName)},
+			},
+			out: out{
+				output: strings.TrimLeft(`
+a
+├── i
+│   └── u
+│       └── k
+└── kk
+    └── t
 `, "\n"),
 				err: nil,
 			},
@@ -179,6 +228,51 @@ WalkerNode's methods called...
 	Level    : 3
 	Path     : e/o/g
 	HasChild : false
+`, "\n"),
+				err: nil,
+			},
+		},
+		{
+			name: "case(succeeded/compact chains)",
+			in: in{
+				input: strings.NewReader(strings.TrimSpace(`
+- a
+	- i
+		- u
+			- k
+			- kk`)),
+				options: []gtree.Option{gtree.WithCompactChains()},
+			},
+			out: out{
+				output: strings.TrimLeft(`
+WalkerNode's methods called...
+	Name     : a
+	Branch   : 
+	Row      : a
+	Level    : 1
+	Path     : a
+	HasChild : true
+WalkerNode's methods called...
+	Name     : i/u
+	Branch   : └──
+	Row      : └── i/u
+	Level    : 2
+	Path     : a/i/u
+	HasChild : true
+WalkerNode's methods called...
+	Name     : k
+	Branch   :     ├──
+	Row      :     ├── k
+	Level    : 3
+	Path     : a/i/u/k
+	HasChild : false
+WalkerNode's methods called...
+	Name     : kk
+	Branch   :     └──
+	Row      :     └── kk
+	Level    : 3
+	Path     : a/i/u/kk
+	HasChild : false
 `, "\n"),
 				err: nil,
 			},
@@ -214,3 +308,29 @@ WalkerNode's methods called...
 		})
 	}
 }
+
+func TestWalk_meta(t *testing.T) {
+	input := strings.NewReader(strings.TrimSpace(`
+- repo {owner: core}
+	- api.go {owner: team-a, size: 10} # handlers
+	- api.go {owner: team-b}
+	- web`))
+
+	buf := &bytes.Buffer{}
+	callback := func(wn *gtree.WalkerNode) error {
+		fmt.Fprintf(buf, "%s: owner=%v size=%v\n", wn.Path(), wn.Meta("owner"), wn.Meta("size"))
+		return nil
+	}
+	if err := gtree.Walk(input, callback); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.TrimLeft(`
+repo: owner=core size=<nil>
+repo/api.go: owner=team-b size=10
+repo/web: owner=<nil> size=<nil>
+`, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("\ngot: \n%s\nwant: \n%s", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+//go:build !tinywasm
+
+package gtree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WithMaxDepth returns function for hiding the nodes deeper than depth when outputting or walking tree.
+// The root is depth 0. The children of a node at the depth are summarized in a row like "… 1,204 more (37 dirs, 1,167 files)",
+// and JSON, YAML and TOML have the number of hidden nodes in the "truncated" field instead of the row.
+// Nodes with children are counted as dirs. Leaf nodes are counted as files, or according to WithFileExtensions if it is specified.
+// Mkdir and Verify ignore this option. Default is unlimited.
+func WithMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.maxDepth = depth
+	}
+}
+
+// WithMaxChildren returns function for hiding the children of each node after the first n when outputting or walking tree.
+// The hidden children and their descendants are summarized in the same way as WithMaxDepth.
+// When used with WithSort, the first n children in the sorted order are kept.
+// Mkdir and Verify ignore this option. Default is unlimited.
+func WithMaxChildren(n int) Option {
+	return func(c *config) {
+		c.maxChildren = n
+	}
+}
+
+func (c *config) truncation() truncation {
+	return truncation{
+		maxDepth:       c.maxDepth,
+		maxChildren:    c.maxChildren,
+		fileConsiderer: newFileConsiderer(c.fileExtensions),
+	}
+}
+
+// WithMaxDepth/WithMaxChildren で隠すノードの基準。0 の場合は制限しない
+type truncation struct {
+	maxDepth       int
+	maxChildren    int
+	fileConsiderer *fileConsiderer
+}
+
+func (t truncation) enabled() bool {
+	return t.maxDepth > 0 || t.maxChildren > 0
+}
+
+// 子を切り詰めて、隠したノードを1つの集計ノードにまとめる。子の並び替えの後に呼ぶ
+func (t truncation) truncateChildren(current *Node) {
+	if !t.enabled() || !current.hasChild() || current.isSummary {
+		return
+	}
+
+	keep := len(current.children)
+	if t.maxDepth > 0 && current.Depth() >= uint(t.maxDepth) {
+		keep = 0
+	} else if t.maxChildren > 0 && keep > t.maxChildren {
+		keep = t.maxChildren
+	}
+	if keep == len(current.children) {
+		return
+	}
+
+	hidden := current.children[keep:]
+	dirs, files := 0, 0
+	for _, h := range hidden {
+		d, f := t.count(h)
+		dirs += d
+		files += f
+	}
+
+	var idx uint
+	for _, c := range current.children[:keep] {
+		idx = max(idx, c.index)
+	}
+	summary := newNode(summaryText(dirs, files), current.hierarchy+1, idx+1)
+	summary.isSummary = true
+	summary.setParent(current)
+
+	current.children = append(current.children[:keep:keep], summary)
+	current.truncated = dirs + files
+}
+
+func (t truncation) count(current *Node) (dirs, files int) {
+	if t.fileConsiderer.countsAsFile(current) {
+		files++
+	} else {
+		dirs++
+	}
+	for _, child := range current.children {
+		d, f := t.count(child)
+		dirs += d
+		files += f
+	}
+	return dirs, files
+}
+
+// e.g. … 1,204 more (37 dirs, 1,167 files)
+func summaryText(dirs, files int) string {
+	counts := []string{}
+	if dirs > 0 {
+		counts = append(counts, plural(dirs, "dir", "dirs"))
+	}
+	if files > 0 {
+		counts = append(counts, plural(files, "file", "files"))
+	}
+	return fmt.Sprintf("… %s more (%s)", formatCount(dirs+files), strings.Join(counts, ", "))
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return formatCount(n) + " " + singular
+	}
+	return formatCount(n) + " " + plural
+}
+
+// 3桁ごとにカンマで区切る
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
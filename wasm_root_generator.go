@@ -10,14 +10,14 @@ import (
 type rootGenerator struct {
 	counter       *counter
 	scanner       *bufio.Scanner
-	nodeGenerator *nodeGenerator
+	nodeGenerator nodeGenerator
 }
 
 func newRootGenerator(r io.Reader) *rootGenerator {
 	return &rootGenerator{
 		counter:       newCounter(),
 		scanner:       bufio.NewScanner(r),
-		nodeGenerator: newNodeGenerator(),
+		nodeGenerator: newMarkdownNodeGenerator(),
 	}
 }
 
@@ -27,8 +27,18 @@ func (rg *rootGenerator) generate() ([]*Node, error) {
 		roots []*Node
 	)
 
+	contents := newContentReader()
+	line := 0
 	for rg.scanner.Scan() {
-		currentNode, err := rg.nodeGenerator.generate(rg.scanner.Text(), rg.counter.next())
+		line++
+		row := rg.scanner.Text()
+		if ok, err := contents.read(row, line); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+
+		currentNode, err := rg.nodeGenerator.generate(row, line, rg.counter.next())
 		if err != nil {
 			return nil, err
 		}
@@ -41,6 +51,7 @@ func (rg *rootGenerator) generate() ([]*Node, error) {
 			roots = append(roots, currentNode)
 			stack = newStack()
 			stack.push(currentNode)
+			contents.setTarget(currentNode, row)
 			continue
 		}
 
@@ -48,8 +59,11 @@ func (rg *rootGenerator) generate() ([]*Node, error) {
 			return nil, errNilStack
 		}
 
-		stack.dfs(currentNode)
+		contents.setTarget(stack.dfs(currentNode), row)
+	}
+	if err := rg.scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	return roots, rg.scanner.Err()
+	return roots, contents.close()
 }
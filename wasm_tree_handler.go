@@ -8,7 +8,10 @@ import (
 
 // Output outputs a tree to w with r as Markdown format input.
 func Output(w io.Writer, r io.Reader, options ...Option) error {
-	cfg := newConfig(options)
+	cfg, err := newConfig(options)
+	if err != nil {
+		return err
+	}
 
 	rg := newRootGenerator(r)
 	roots, err := rg.generate()
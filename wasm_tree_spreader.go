@@ -40,6 +40,11 @@ const (
 	encodeJSON
 	encodeYAML
 	encodeTOML
+	encodeMarkdown
+	encodeMermaid
+	encodeDOT
+	encodeHTML
+	encodeTemplate
 )
 
 type defaultSpreader struct{}